@@ -1,71 +1,210 @@
-package main
-
-import (
-	"database/sql"
-	"go-clean-v3/internal/config"
-	"go-clean-v3/internal/infrastructure/delivery/http"
-	"go-clean-v3/internal/infrastructure/delivery/http/handler"
-	"go-clean-v3/internal/infrastructure/external/jwt"
-	"go-clean-v3/internal/infrastructure/persistence/gorm"
-	"go-clean-v3/internal/infrastructure/persistence/migrate"
-	"go-clean-v3/internal/usecase/auth"
-	"go-clean-v3/internal/usecase/user"
-	"go-clean-v3/pkg/logger"
-)
-
-func main() {
-	// Initialize Logger
-	logger.Init()
-	logger.Info("🚀 Starting application", nil)
-
-	// Load Configuration
-	cfg := config.Load()
-	logger.Info("Configuration loaded", map[string]interface{}{
-		"env": cfg.Environment,
-		"port": cfg.Port,
-	})
-
-	// Open RawDB connection for migrations
-	db, err := sql.Open("mysql", cfg.DatabaseURL)
-	if err != nil {
-		logger.Fatal("Failed to connect to database", map[string]interface{}{"error": err.Error()})
-	}
-	defer db.Close()
-
-	// Run migrations
-	if err := migrate.Run(db, "../migrations"); err != nil {
-		logger.Fatal("❌ Migration failed", map[string]interface{}{"error": err.Error()})
-	}
-
-	// Initialize GORM DB
-	gormDB, err := gorm.NewDB(cfg)
-	if err != nil {
-		logger.Fatal("Failed to initialize GORM DB", map[string]interface{}{"error": err.Error()})
-	}
-	
-	// Set up reposiotories
-	userRepo := gorm.NewUserRepository(gormDB)
-
-	// Set up external services
-	jwtService := jwt.NewJWTService(cfg.JWTSecret)
-
-	// Set up usecases
-	userUsecase := user.NewUserUsecase(userRepo, jwtService)
-	authUsecase := auth.NewAuthUsecase(userRepo, jwtService)
-
-	// set up handlers
-	userHandler := handler.NewUserHandler(userUsecase)
-	authHandler := handler.NewAuthHandler(authUsecase)
-
-	// Group handlers
-	handlers := &handler.Handlers{
-		UserHandler: userHandler,
-		AuthHandler: authHandler,
-	}
-
-	// Crete and start server
-	srv := http.NewServer(cfg)
-	srv.RegisterRoutes(handlers)
-	srv.Run(cfg.Port)
-
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/internal/infrastructure/cache"
+	"go-clean-v3/internal/infrastructure/delivery/http"
+	"go-clean-v3/internal/infrastructure/delivery/http/handler"
+	"go-clean-v3/internal/infrastructure/external/jwt"
+	"go-clean-v3/internal/infrastructure/external/publisher"
+	"go-clean-v3/internal/infrastructure/persistence/gorm"
+	"go-clean-v3/internal/infrastructure/persistence/migrate"
+	"go-clean-v3/migrations"
+	"go-clean-v3/internal/usecase/audit"
+	"go-clean-v3/internal/usecase/auth"
+	"go-clean-v3/internal/usecase/eventbus"
+	"go-clean-v3/internal/usecase/outbox"
+	"go-clean-v3/internal/usecase/trash"
+	"go-clean-v3/internal/usecase/user"
+	"go-clean-v3/internal/usecase/workspace"
+	"go-clean-v3/pkg/logger"
+	"go-clean-v3/pkg/metrics"
+	"go-clean-v3/pkg/tracing"
+	"os"
+	"time"
+)
+
+func main() {
+	// Subcommands share the binary with the server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		case "seed":
+			os.Exit(runSeed(os.Args[2:]))
+		case "reencrypt":
+			os.Exit(runReencrypt(os.Args[2:]))
+		}
+	}
+
+	runServer()
+}
+
+func runServer() {
+	// Initialize Logger
+	logger.Init()
+	logger.Info("🚀 Starting application", nil)
+
+	// Load Configuration
+	cfg := config.Load()
+	logger.Info("Configuration loaded", map[string]interface{}{
+		"env": cfg.Environment,
+		"port": cfg.Port,
+	})
+
+	// Set up tracing before anything that starts spans
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		ServiceName: cfg.AppName,
+		Environment: cfg.Environment,
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Initialize GORM DB, waiting for the database to come up
+	gormDB, err := gorm.NewDB(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize GORM DB", map[string]interface{}{"error": err.Error()})
+	}
+
+	db, err := gormDB.DB()
+	if err != nil {
+		logger.Fatal("Failed to connect to database", map[string]interface{}{"error": err.Error()})
+	}
+	defer gorm.Close(gormDB)
+
+	// Run the embedded migrations unless they are applied separately with `migrate up`
+	if cfg.DatabaseAutoMigrate {
+		if err := migrate.Run(cfg.DatabaseURL, migrations.FS); err != nil {
+			logger.Fatal("❌ Migration failed", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	// Readiness waits for the database and the migrations
+	latestMigration, err := migrate.Latest(migrations.FS)
+	if err != nil {
+		logger.Fatal("Failed to read migrations", map[string]interface{}{"error": err.Error()})
+	}
+	healthHandler, err := handler.NewHealthHandler(cfg.HealthCheckTimeout, cfg.Environment != "production")
+	if err != nil {
+		logger.Fatal("Failed to configure health checks", map[string]interface{}{"error": err.Error()})
+	}
+	healthHandler.Register("database", db.PingContext)
+	healthHandler.Register("migrations", func(ctx context.Context) error {
+		version, dirty, ok, err := migrate.AppliedVersion(ctx, db)
+		switch {
+		case err != nil:
+			return err
+		case !ok || version < latestMigration:
+			return fmt.Errorf("schema is at version %d, expected %d", version, latestMigration)
+		case dirty:
+			return fmt.Errorf("migration %d failed halfway", version)
+		}
+		return nil
+	})
+	
+	// Set up reposiotories
+	userRepo := gorm.NewUserRepository(gormDB)
+	switch cfg.CacheDriver {
+	case "memory":
+		userRepo = cache.NewUserRepository(userRepo, cache.NewMemoryCache(cfg.CacheSize), cfg.CacheTTL, gorm.EncryptionService(gormDB))
+	case "redis":
+		redisClient, err := cache.NewRedisClient(cfg.RedisURL)
+		if err != nil {
+			logger.Fatal("Failed to configure Redis", map[string]interface{}{"error": err.Error()})
+		}
+		defer redisClient.Close()
+		healthHandler.Register("cache", func(ctx context.Context) error { return redisClient.Ping(ctx).Err() })
+		userRepo = cache.NewUserRepository(userRepo, cache.NewRedisCache(redisClient, cfg.AppName+":"), cfg.CacheTTL, gorm.EncryptionService(gormDB))
+	}
+	auditRepo := gorm.NewAuditRepository(gormDB)
+	workspaceRepo := gorm.NewWorkspaceRepository(gormDB)
+	outboxRepo := gorm.NewOutboxRepository(gormDB)
+	txManager := gorm.NewTransactionManager(gormDB)
+
+	// Set up external services
+	jwtService := jwt.NewJWTService(cfg.JWTSecret)
+	var eventPublisher event.Publisher
+	switch cfg.OutboxPublisher {
+	case "webhook":
+		eventPublisher = publisher.NewWebhookPublisher(cfg.OutboxWebhookURL, 10*time.Second)
+	default:
+		eventPublisher = publisher.NewLogPublisher()
+	}
+
+	// Events go to in-process subscribers and on to the outbox
+	eventBus, err := eventbus.NewBus(outboxRepo, cfg.EventBusWorkers, cfg.EventBusQueueSize, cfg.EventBusMaxAttempts)
+	if err != nil {
+		logger.Fatal("Failed to configure the event bus", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Set up usecases
+	userUsecase := user.NewUserUsecase(userRepo, jwtService, txManager, auditRepo, eventBus)
+	authUsecase := auth.NewAuthUsecase(userRepo, jwtService, auditRepo)
+	trashUsecase, err := trash.NewTrashUsecase(userRepo, txManager, auditRepo, eventBus, cfg.TrashRetention, cfg.TrashPurgeInterval)
+	if err != nil {
+		logger.Fatal("Failed to configure the trash", map[string]interface{}{"error": err.Error()})
+	}
+	auditUsecase := audit.NewAuditUsecase(auditRepo)
+	workspaceUsecase := workspace.NewWorkspaceUsecase(workspaceRepo, userRepo, jwtService, txManager, auditRepo)
+	outboxRelay, err := outbox.NewRelay(outboxRepo, eventPublisher, outbox.Config{
+		BatchSize:    cfg.OutboxBatchSize,
+		MaxAttempts:  cfg.OutboxMaxAttempts,
+		PollInterval: cfg.OutboxPollInterval,
+		Lease:        cfg.OutboxClaimLease,
+	})
+	if err != nil {
+		logger.Fatal("Failed to configure the outbox relay", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Start background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go trashUsecase.RunPurger(jobsCtx)
+	go outboxRelay.Run(jobsCtx)
+
+	// set up handlers
+	userHandler := handler.NewUserHandler(userUsecase)
+	authHandler := handler.NewAuthHandler(authUsecase)
+	trashHandler := handler.NewTrashHandler(trashUsecase)
+	auditHandler := handler.NewAuditHandler(auditUsecase)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceUsecase)
+	dbStats := func() map[string]sql.DBStats { return gorm.Stats(gormDB) }
+	dbHandler := handler.NewDBHandler(dbStats)
+	if err := metrics.RegisterDBStats(dbStats); err != nil {
+		logger.Fatal("Failed to register database metrics", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Group handlers
+	handlers := &handler.Handlers{
+		UserHandler: userHandler,
+		AuthHandler: authHandler,
+		TrashHandler: trashHandler,
+		AuditHandler: auditHandler,
+		DBHandler: dbHandler,
+		WorkspaceHandler: workspaceHandler,
+		HealthHandler: healthHandler,
+	}
+
+	// Crete and start server
+	srv := http.NewServer(cfg)
+	srv.RegisterRoutes(handlers)
+	srv.Run(cfg.Port)
+
+	// Let async subscribers finish the events of the last requests
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := eventBus.Shutdown(ctx); err != nil {
+		logger.Error("Event bus did not drain", map[string]interface{}{"error": err.Error()})
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Tracing did not flush", map[string]interface{}{"error": err.Error()})
+	}
 }
\ No newline at end of file
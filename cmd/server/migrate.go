@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/infrastructure/persistence/drift"
+	"go-clean-v3/internal/infrastructure/persistence/migrate"
+	"go-clean-v3/migrations"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = `Usage: server migrate <command> [args]
+
+Commands:
+  up             Apply all pending migrations
+  down N         Roll back the last N migrations (default 1)
+  goto V         Migrate up or down to version V
+  status         List migrations and whether they are applied
+  force V        Set the version without running migrations (-1 for none)
+  create NAME    Create an empty up/down migration pair in ./migrations
+  drift          Migrate a scratch database and compare it with the GORM models
+`
+
+// runMigrate runs the migrate subcommand and returns the exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		files, err := migrate.Create("migrations", args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "create failed: %v\n", err)
+			return 1
+		}
+		for _, file := range files {
+			fmt.Println("created", file)
+		}
+		return 0
+	}
+
+	cfg := config.Load()
+	if args[0] == "drift" {
+		return runDrift(cfg.DatabaseURL)
+	}
+
+	mg, err := migrate.New(cfg.DatabaseURL, migrations.FS)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer mg.Close()
+
+	switch args[0] {
+	case "up":
+		err = mg.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				fmt.Fprintf(os.Stderr, "invalid step count %q\n", args[1])
+				return 2
+			}
+		}
+		err = mg.Down(steps)
+	case "goto":
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		version, parseErr := strconv.ParseUint(args[1], 10, 64)
+		if parseErr != nil {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", args[1])
+			return 2
+		}
+		err = mg.Goto(uint(version))
+	case "force":
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		version, parseErr := strconv.Atoi(args[1])
+		if parseErr != nil {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", args[1])
+			return 2
+		}
+		err = mg.Force(version)
+	case "status":
+		err = printStatus(mg)
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s failed: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// runDrift reports differences between the migrated schema and the models and
+// fails when there are any
+func runDrift(dsn string) int {
+	diffs, err := drift.Check(dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "drift check failed: %v\n", err)
+		return 1
+	}
+	if len(diffs) == 0 {
+		fmt.Println("no schema drift")
+		return 0
+	}
+
+	for _, diff := range diffs {
+		fmt.Println(diff)
+	}
+	return 1
+}
+
+func printStatus(mg *migrate.Migrator) error {
+	statuses, err := mg.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, s := range statuses {
+		state := "pending"
+		if s.Dirty {
+			state = "dirty"
+		} else if s.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\n", s.Version, s.Name, state)
+	}
+	return w.Flush()
+}
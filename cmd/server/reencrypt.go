@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/infrastructure/persistence/gorm"
+	"go-clean-v3/pkg/logger"
+	"os"
+)
+
+// runReencrypt rewrites encrypted columns with the active key and returns the
+// exit code. Run it after adding a new ENCRYPTION_ACTIVE_KEY, and before removing
+// the old key from ENCRYPTION_KEYS.
+func runReencrypt(args []string) int {
+	flags := flag.NewFlagSet("reencrypt", flag.ContinueOnError)
+	all := flags.Bool("all", false, "rewrite every row, needed after changing ENCRYPTION_INDEX_KEY")
+	batchSize := flags.Int("batch", 500, "rows read per query")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: server reencrypt [flags]")
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	logger.Init()
+	cfg := config.Load()
+
+	gormDB, err := gorm.NewDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not connect to database: %v\n", err)
+		return 1
+	}
+	defer gorm.Close(gormDB)
+
+	rewritten, skipped, err := gorm.ReencryptUsers(context.Background(), gormDB, *all, *batchSize)
+	fmt.Printf("users: %d rows rewritten, %d skipped after concurrent changes\n", rewritten, skipped)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reencrypt failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/infrastructure/external/jwt"
+	"go-clean-v3/internal/infrastructure/persistence/gorm"
+	"go-clean-v3/internal/seed"
+	"go-clean-v3/internal/usecase/user"
+	"go-clean-v3/pkg/logger"
+	"os"
+)
+
+// runSeed loads seed sets into the configured database and returns the exit code.
+// The database must already be migrated.
+func runSeed(args []string) int {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	users := flags.Int("users", 1000, "number of users created by the load-test set")
+	concurrency := flags.Int("concurrency", 0, "fixtures created at once (default: number of CPUs)")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: server seed [flags] SET...")
+		fmt.Fprintln(os.Stderr, "\nSets:")
+		for _, set := range seed.Sets() {
+			fmt.Fprintf(os.Stderr, "  %-10s %s\n", set.Name, set.Description)
+		}
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	logger.Init()
+	cfg := config.Load()
+
+	gormDB, err := gorm.NewDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not connect to database: %v\n", err)
+		return 1
+	}
+	defer gorm.Close(gormDB)
+
+	userRepo := gorm.NewUserRepository(gormDB)
+	userUsecase := user.NewUserUsecase(userRepo, jwt.NewJWTService(cfg.JWTSecret), gorm.NewTransactionManager(gormDB), gorm.NewAuditRepository(gormDB), gorm.NewOutboxRepository(gormDB))
+	seeder := seed.NewSeeder(userUsecase, userRepo, seed.Options{
+		LoadTestUsers: *users,
+		Concurrency:   *concurrency,
+	})
+
+	if err := seeder.Run(context.Background(), flags.Args()...); err != nil {
+		fmt.Fprintf(os.Stderr, "seed failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -1,30 +1,191 @@
-package config
-
-import (
-	"log"
-
-	"github.com/joho/godotenv"
-	"github.com/spf13/viper"
-)
-
-type Config struct {
-	AppName     string
-	Port        string
-	DatabaseURL string
-	JWTSecret   string
-	Environment string
-}
-
-func Load() *Config {
-	if err := godotenv.Load(); err != nil {
-		log.Printf("[Config-1] No .env file found: %v", err)
-	}
-
-	return &Config{
-		AppName:     viper.GetString("APP_NAME"),
-		Port:        viper.GetString("APP_PORT"),
-		DatabaseURL: viper.GetString("DB_URL"),
-		JWTSecret:   viper.GetString("JWT_SECRET"),
-		Environment: viper.GetString("APP_ENV"),
-	}
-}
\ No newline at end of file
+package config
+
+import (
+	"go-clean-v3/pkg/logger"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	AppName     string
+	Port        string
+	DatabaseURL string
+	JWTSecret   string
+	Environment string
+
+	// Connection pool settings, applied to the primary and every replica
+	DatabaseMaxOpenConns    int
+	DatabaseMaxIdleConns    int
+	DatabaseConnMaxLifetime time.Duration
+	DatabaseConnMaxIdleTime time.Duration
+	// DatabaseConnectTimeout is how long startup keeps retrying to reach the database
+	DatabaseConnectTimeout time.Duration
+	// DatabaseAutoMigrate applies pending migrations when the server starts
+	DatabaseAutoMigrate bool
+
+	// DatabaseReplicaURLs are read replicas of DatabaseURL; reads are spread across the healthy ones
+	DatabaseReplicaURLs []string
+	// DatabaseReplicaHealthInterval is how often replicas are pinged
+	DatabaseReplicaHealthInterval time.Duration
+
+	// CacheDriver selects the repository cache: memory, redis or none
+	CacheDriver string
+	CacheTTL    time.Duration
+	// CacheSize is the maximum number of entries of the memory cache
+	CacheSize int
+	RedisURL  string
+
+	// OutboxPublisher selects where outbox events go: log or webhook
+	OutboxPublisher    string
+	OutboxWebhookURL   string
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	// OutboxMaxAttempts is how often a message is tried before it is dead-lettered
+	OutboxMaxAttempts int
+	// OutboxClaimLease is how long a relay may take to publish a claimed batch
+	// before other instances may claim the messages again
+	OutboxClaimLease time.Duration
+
+	// EventBusWorkers is the number of goroutines running async event subscribers
+	EventBusWorkers   int
+	EventBusQueueSize int
+	// EventBusMaxAttempts is how often an async subscriber is tried before the event is dead-lettered
+	EventBusMaxAttempts int
+	// ShutdownTimeout bounds how long in-flight requests and events may take to finish
+	ShutdownTimeout time.Duration
+	// ShutdownDrainDelay is how long the server keeps serving after readiness
+	// starts failing, so the orchestrator can stop routing traffic to it first.
+	// It should be longer than the readiness probe period.
+	ShutdownDrainDelay time.Duration
+	// HealthCheckTimeout bounds every readiness check
+	HealthCheckTimeout time.Duration
+
+	// EncryptionKeys are the master keys for encrypted columns as id:base64 pairs.
+	// New values use EncryptionActiveKey; the others are kept to read older values.
+	EncryptionKeys      string
+	EncryptionActiveKey string
+	// EncryptionIndexKey is the base64 key for the blind indexes of encrypted columns
+	EncryptionIndexKey string
+
+	// TrashRetention is how long soft-deleted rows stay restorable before they are purged
+	TrashRetention time.Duration
+	// TrashPurgeInterval is how often the purge job looks for expired rows
+	TrashPurgeInterval time.Duration
+
+	// AccessLogFields selects the fields of access log lines; empty logs the defaults
+	AccessLogFields []string
+	// AccessLogSkipPaths are request paths or route templates left out of the access log
+	AccessLogSkipPaths []string
+
+	// MetricsPort serves /metrics on a separate admin port; empty serves it on Port
+	MetricsPort string
+
+	// TracingExporter selects where spans go: none, stdout or otlp
+	TracingExporter string
+	// TracingEndpoint is the OTLP/HTTP collector URL used by the otlp exporter
+	TracingEndpoint string
+	// TracingSampleRatio is the share of new traces that are recorded, from 0 to 1
+	TracingSampleRatio float64
+}
+
+func Load() *Config {
+	if err := godotenv.Load(); err != nil {
+		logger.Info("[Config-1] No .env file found", map[string]interface{}{"error": err.Error()})
+	}
+
+	viper.AutomaticEnv()
+	viper.SetDefault("TRASH_RETENTION", "720h")
+	viper.SetDefault("TRASH_PURGE_INTERVAL", "1h")
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 100)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 10)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", "30m")
+	viper.SetDefault("DB_CONN_MAX_IDLE_TIME", "5m")
+	viper.SetDefault("DB_CONNECT_TIMEOUT", "60s")
+	viper.SetDefault("DB_AUTO_MIGRATE", true)
+	viper.SetDefault("DB_REPLICA_HEALTH_INTERVAL", "10s")
+	viper.SetDefault("CACHE_DRIVER", "memory")
+	viper.SetDefault("CACHE_TTL", "5m")
+	viper.SetDefault("CACHE_SIZE", 10000)
+	viper.SetDefault("OUTBOX_PUBLISHER", "log")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 12)
+	viper.SetDefault("OUTBOX_CLAIM_LEASE", "5m")
+	viper.SetDefault("EVENT_BUS_WORKERS", 4)
+	viper.SetDefault("EVENT_BUS_QUEUE_SIZE", 1000)
+	viper.SetDefault("EVENT_BUS_MAX_ATTEMPTS", 5)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", "10s")
+	viper.SetDefault("SHUTDOWN_DRAIN_DELAY", "5s")
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	viper.SetDefault("ACCESS_LOG_SKIP_PATHS", "/healthz,/readyz,/metrics")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_ENDPOINT", "http://localhost:4318")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+
+	return &Config{
+		AppName:     viper.GetString("APP_NAME"),
+		Port:        viper.GetString("APP_PORT"),
+		DatabaseURL: viper.GetString("DB_URL"),
+		JWTSecret:   viper.GetString("JWT_SECRET"),
+		Environment: viper.GetString("APP_ENV"),
+
+		DatabaseMaxOpenConns:    viper.GetInt("DB_MAX_OPEN_CONNS"),
+		DatabaseMaxIdleConns:    viper.GetInt("DB_MAX_IDLE_CONNS"),
+		DatabaseConnMaxLifetime: viper.GetDuration("DB_CONN_MAX_LIFETIME"),
+		DatabaseConnMaxIdleTime: viper.GetDuration("DB_CONN_MAX_IDLE_TIME"),
+		DatabaseConnectTimeout:  viper.GetDuration("DB_CONNECT_TIMEOUT"),
+		DatabaseAutoMigrate:     viper.GetBool("DB_AUTO_MIGRATE"),
+
+		DatabaseReplicaURLs:           splitList(viper.GetString("DB_REPLICA_URLS")),
+		DatabaseReplicaHealthInterval: viper.GetDuration("DB_REPLICA_HEALTH_INTERVAL"),
+
+		CacheDriver: viper.GetString("CACHE_DRIVER"),
+		CacheTTL:    viper.GetDuration("CACHE_TTL"),
+		CacheSize:   viper.GetInt("CACHE_SIZE"),
+		RedisURL:    viper.GetString("REDIS_URL"),
+
+		OutboxPublisher:    viper.GetString("OUTBOX_PUBLISHER"),
+		OutboxWebhookURL:   viper.GetString("OUTBOX_WEBHOOK_URL"),
+		OutboxPollInterval: viper.GetDuration("OUTBOX_POLL_INTERVAL"),
+		OutboxBatchSize:    viper.GetInt("OUTBOX_BATCH_SIZE"),
+		OutboxMaxAttempts:  viper.GetInt("OUTBOX_MAX_ATTEMPTS"),
+		OutboxClaimLease:   viper.GetDuration("OUTBOX_CLAIM_LEASE"),
+
+		EventBusWorkers:     viper.GetInt("EVENT_BUS_WORKERS"),
+		EventBusQueueSize:   viper.GetInt("EVENT_BUS_QUEUE_SIZE"),
+		EventBusMaxAttempts: viper.GetInt("EVENT_BUS_MAX_ATTEMPTS"),
+		ShutdownTimeout:     viper.GetDuration("SHUTDOWN_TIMEOUT"),
+		ShutdownDrainDelay:  viper.GetDuration("SHUTDOWN_DRAIN_DELAY"),
+		HealthCheckTimeout:  viper.GetDuration("HEALTH_CHECK_TIMEOUT"),
+
+		EncryptionKeys:      viper.GetString("ENCRYPTION_KEYS"),
+		EncryptionActiveKey: viper.GetString("ENCRYPTION_ACTIVE_KEY"),
+		EncryptionIndexKey:  viper.GetString("ENCRYPTION_INDEX_KEY"),
+
+		TrashRetention:     viper.GetDuration("TRASH_RETENTION"),
+		TrashPurgeInterval: viper.GetDuration("TRASH_PURGE_INTERVAL"),
+
+		AccessLogFields:    splitList(viper.GetString("ACCESS_LOG_FIELDS")),
+		AccessLogSkipPaths: splitList(viper.GetString("ACCESS_LOG_SKIP_PATHS")),
+
+		MetricsPort: viper.GetString("METRICS_PORT"),
+
+		TracingExporter:    viper.GetString("TRACING_EXPORTER"),
+		TracingEndpoint:    viper.GetString("TRACING_ENDPOINT"),
+		TracingSampleRatio: viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+	}
+}
+
+// splitList parses a comma separated value, ignoring empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
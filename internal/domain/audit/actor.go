@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+// Actor identifies who performs a request and from where
+type Actor struct {
+	UserID    int64
+	IP        string
+	UserAgent string
+}
+
+type actorKey struct{}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx; UserID is 0 for anonymous requests
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
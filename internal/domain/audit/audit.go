@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"context"
+	"go-clean-v3/internal/domain/query"
+	"reflect"
+	"time"
+)
+
+const (
+	ActionLogin           = "auth.login"
+	ActionLoginFailed     = "auth.login_failed"
+	ActionRegister        = "user.register"
+	ActionProfileUpdate   = "user.profile_update"
+	ActionPasswordChange  = "user.password_change"
+	ActionAccountDelete   = "user.delete"
+	ActionUserRestore     = "admin.user_restore"
+	ActionRoleChange      = "admin.role_change"
+	ActionTrashPurge      = "system.trash_purge"
+	ActionWorkspaceCreate = "workspace.create"
+	ActionMemberAdd       = "workspace.member_add"
+
+	EntityUser      = "user"
+	EntityWorkspace = "workspace"
+)
+
+// Entry is a single immutable audit record. Before and After only hold the fields that changed.
+type Entry struct {
+	ID         int64                  `json:"id"`
+	ActorID    *int64                 `json:"actor_id"`
+	Action     string                 `json:"action"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   string                 `json:"entity_id"`
+	IP         string                 `json:"ip"`
+	UserAgent  string                 `json:"user_agent"`
+	Before     map[string]interface{} `json:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// AuditLogger records audit entries. Called with a transactional context the entry
+// is written in that transaction, so it is only kept if the audited change is.
+// Actor details missing from the entry are taken from the context.
+type AuditLogger interface {
+	Log(ctx context.Context, entry *Entry) error
+}
+
+// AuditRepositoryInterface is the append-only audit store
+type AuditRepositoryInterface interface {
+	AuditLogger
+	List(ctx context.Context, spec query.Spec) (*query.Page[*Entry], error)
+}
+
+// ListSchema is the set of fields audit lists can be sorted and filtered by
+var ListSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":          {Type: query.Int, Sortable: true, Filterable: true},
+		"actor_id":    {Type: query.Int, Filterable: true},
+		"action":      {Type: query.String, Sortable: true, Filterable: true},
+		"entity_type": {Type: query.String, Filterable: true},
+		"entity_id":   {Type: query.String, Filterable: true},
+		"ip":          {Type: query.String, Filterable: true},
+		"created_at":  {Type: query.Time, Sortable: true, Filterable: true},
+	},
+	DefaultSort: []query.Sort{{Field: "created_at", Direction: query.Desc}},
+}
+
+// Diff returns the entries of before and after whose values differ
+func Diff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+
+	for k, v := range before {
+		if !reflect.DeepEqual(v, after[k]) {
+			changedBefore[k] = v
+			if av, ok := after[k]; ok {
+				changedAfter[k] = av
+			}
+		}
+	}
+	for k, v := range after {
+		if _, ok := before[k]; !ok {
+			changedAfter[k] = v
+		}
+	}
+
+	return changedBefore, changedAfter
+}
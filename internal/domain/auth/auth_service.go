@@ -1,7 +1,10 @@
-package auth
-
-import "go-clean-v3/internal/domain/user"
-
-type AuthServiceInterface interface {
-	GenerateToken(u *user.User) (string, error)
+package auth
+
+import "go-clean-v3/internal/domain/user"
+
+type AuthServiceInterface interface {
+	GenerateToken(u *user.User) (string, error)
+	// GenerateWorkspaceToken is GenerateToken with the workspace with the public ID
+	// workspaceID as the default tenant
+	GenerateWorkspaceToken(u *user.User, workspaceID string) (string, error)
 }
\ No newline at end of file
@@ -1,11 +1,121 @@
-package errors
-
-import "errors"
-
-var (
-    ErrUnauthorized = errors.New("unauthorized")
-    ErrForbidden    = errors.New("forbidden")
-    ErrNotFound     = errors.New("resource not found")
-    ErrInvalidInput = errors.New("invalid input")
-    ErrInternal     = errors.New("internal server error")
-)
+package errors
+
+import "errors"
+
+// Kind is the broad category of an AppError, which decides how it is reported
+type Kind string
+
+const (
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindInternal     Kind = "internal"
+	// KindPreconditionFailed is for requests made against a version of a
+	// resource that is no longer current, e.g. a stale If-Match
+	KindPreconditionFailed Kind = "precondition_failed"
+)
+
+// FieldError describes what is wrong with a single input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// AppError is an error clients can act on. Code is stable and machine readable,
+// Message is meant for humans and Err is the cause, which is never shown to
+// clients.
+//
+// Errors are declared once as package level values and matched with errors.Is,
+// which compares codes, so copies made by Wrap and WithDetails still match.
+type AppError struct {
+	Code    string
+	Message string
+	Kind    Kind
+	Details []FieldError
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an AppError with the same code
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by err
+func (e *AppError) Wrap(err error) *AppError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithDetails returns a copy of e with details about the offending fields
+func (e *AppError) WithDetails(details ...FieldError) *AppError {
+	c := *e
+	c.Details = append(append([]FieldError(nil), e.Details...), details...)
+	return &c
+}
+
+func New(kind Kind, code string, message string) *AppError {
+	return &AppError{Code: code, Message: message, Kind: kind}
+}
+
+func NotFound(code string, message string) *AppError {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code string, message string) *AppError {
+	return New(KindConflict, code, message)
+}
+
+func Validation(code string, message string, details ...FieldError) *AppError {
+	e := New(KindValidation, code, message)
+	e.Details = details
+	return e
+}
+
+func Unauthorized(code string, message string) *AppError {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code string, message string) *AppError {
+	return New(KindForbidden, code, message)
+}
+
+func Internal(code string, message string) *AppError {
+	return New(KindInternal, code, message)
+}
+
+// KindOf returns the kind of the AppError in err's chain, or KindInternal when
+// there is none
+func KindOf(err error) Kind {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
+
+var (
+	ErrUnauthorized = Unauthorized("unauthorized", "unauthorized")
+	ErrForbidden    = Forbidden("forbidden", "forbidden")
+	ErrNotFound     = NotFound("not_found", "resource not found")
+	ErrInvalidInput = Validation("invalid_input", "invalid input")
+	ErrInternal     = Internal("internal", "internal server error")
+	ErrConflict     = Conflict("version_conflict", "resource was modified concurrently")
+	// ErrPreconditionFailed is ErrConflict for versions the client asked for
+	ErrPreconditionFailed = New(KindPreconditionFailed, "precondition_failed", "resource does not match the expected version")
+)
@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of writes the database rejects. Repositories return them inside a
+// ConstraintError, so callers can match them with errors.Is.
+var (
+	ErrDuplicate    = errors.New("resource already exists")
+	ErrReference    = errors.New("referenced resource does not exist or is still in use")
+	ErrMissingValue = errors.New("required value is missing")
+	// ErrDeadlock means the transaction was rolled back to break a deadlock and may be retried
+	ErrDeadlock = errors.New("transaction deadlocked")
+)
+
+// ConstraintError is a database error translated to one of the kinds above.
+// Constraint names the index, constraint or column involved when the database
+// reports it.
+type ConstraintError struct {
+	Kind       error
+	Constraint string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Constraint == "" {
+		return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%v (%s): %v", e.Kind, e.Constraint, e.Err)
+}
+
+// Unwrap exposes both the kind and the driver error
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
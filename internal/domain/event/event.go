@@ -0,0 +1,18 @@
+package event
+
+import "context"
+
+// Event is something that happened to an aggregate that other systems may react to
+type Event interface {
+	// EventName identifies the kind of event, such as "user.registered"
+	EventName() string
+	AggregateType() string
+	AggregateID() string
+}
+
+// Recorder stores events for publishing. Called with a transactional context the
+// events are written in that transaction, so they are only published if the
+// change that raised them is committed.
+type Recorder interface {
+	Record(ctx context.Context, events ...Event) error
+}
@@ -0,0 +1,53 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrClaimLost is returned when a message is marked after another relay has
+// claimed it again, because the claim had expired
+var ErrClaimLost = errors.New("outbox: message was claimed by another relay")
+
+// Message is an event waiting in the outbox. IdempotencyKey is unique per event
+// and stays the same across retries, so consumers can drop duplicates. RequestID
+// is the request the event was recorded in, if any.
+type Message struct {
+	ID             int64           `json:"id"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	AggregateType  string          `json:"aggregate_type"`
+	AggregateID    string          `json:"aggregate_id"`
+	EventType      string          `json:"event_type"`
+	RequestID      string          `json:"request_id,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	CreatedAt      time.Time       `json:"created_at"`
+
+	// ClaimToken identifies the claim the message was handed out with, and
+	// ClaimedUntil is when that claim expires
+	ClaimToken   string    `json:"-"`
+	ClaimedUntil time.Time `json:"-"`
+}
+
+// OutboxRepositoryInterface is the transactional outbox
+type OutboxRepositoryInterface interface {
+	Recorder
+	// ClaimPending reserves up to limit messages that are due, at most the oldest
+	// unpublished one per aggregate, for lease. Other relays skip them until the
+	// claim is settled with MarkPublished or MarkFailed, or the lease runs out.
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*Message, error)
+	// MarkPublished settles the claim on msg as delivered. It returns ErrClaimLost
+	// when msg was claimed again in the meantime.
+	MarkPublished(ctx context.Context, msg *Message) error
+	// MarkFailed settles the claim on msg as a failed attempt; the message is
+	// retried at next, or dead-lettered when dead is true. It returns ErrClaimLost
+	// when msg was claimed again in the meantime.
+	MarkFailed(ctx context.Context, msg *Message, cause error, next time.Time, dead bool) error
+}
+
+// Publisher delivers outbox messages to other systems
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cursor is the decoded form of Spec.Cursor. Values holds the sort field values of the
+// last row on the previous page followed by its ID, which breaks ties between equal rows.
+// A nil value stands for NULL.
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+func sortKey(sorts []Sort) string {
+	parts := make([]string, 0, len(sorts))
+	for _, s := range sorts {
+		parts = append(parts, s.Field+":"+string(s.Direction))
+	}
+	return strings.Join(parts, ",")
+}
+
+// EncodeCursor builds an opaque cursor pointing after the row with the given sort values and ID
+func EncodeCursor(sorts []Sort, values []interface{}, id int64) string {
+	encoded := make([]interface{}, 0, len(values)+1)
+	for _, v := range values {
+		if t, ok := v.(time.Time); ok {
+			v = t.UTC().Format(time.RFC3339Nano)
+		}
+		encoded = append(encoded, v)
+	}
+	encoded = append(encoded, id)
+
+	raw, _ := json.Marshal(cursor{Sort: sortKey(sorts), Values: encoded})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor returns the typed sort values and ID stored in spec.Cursor.
+// A cursor is only valid for the sort order it was created with.
+func (s Schema) DecodeCursor(spec Spec) ([]interface{}, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(spec.Cursor)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	// Numbers are kept as json.Number, as float64 cannot hold every int64 ID
+	var c cursor
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&c); err != nil {
+		return nil, 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if c.Sort != sortKey(spec.Sort) || len(c.Values) != len(spec.Sort)+1 {
+		return nil, 0, fmt.Errorf("%w: cursor does not match the requested sort", ErrInvalidQuery)
+	}
+
+	values := make([]interface{}, 0, len(spec.Sort))
+	for i, sort := range spec.Sort {
+		v, err := s.typedValue(sort.Field, c.Values[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		values = append(values, v)
+	}
+
+	id, err := cursorInt(c.Values[len(spec.Sort)])
+	if err != nil {
+		return nil, 0, err
+	}
+	return values, id, nil
+}
+
+func cursorInt(v interface{}) (int64, error) {
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+}
+
+func (s Schema) typedValue(field string, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch s.Fields[field].Type {
+	case Int:
+		return cursorInt(v)
+	case Bool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case Time:
+		if str, ok := v.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, str); err == nil {
+				return t, nil
+			}
+		}
+	default:
+		if str, ok := v.(string); ok {
+			return str, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var cursorSchema = Schema{
+	Fields: map[string]Field{
+		"count":      {Type: Int, Sortable: true},
+		"name":       {Type: String, Sortable: true},
+		"created_at": {Type: Time, Sortable: true},
+	},
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	sorts := []Sort{{Field: "count", Direction: Desc}, {Field: "name", Direction: Asc}, {Field: "created_at", Direction: Asc}}
+	created := time.Date(2024, 5, 1, 12, 30, 0, 123, time.UTC)
+	// Both integers are above 2^53, where float64 starts losing precision
+	const count, id = int64(1<<53 + 1), int64(1<<62 + 7)
+
+	spec := Spec{Sort: sorts, Cursor: EncodeCursor(sorts, []interface{}{count, nil, created}, id)}
+	values, gotID, err := cursorSchema.DecodeCursor(spec)
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+
+	if gotID != id {
+		t.Errorf("id = %d, want %d", gotID, id)
+	}
+	if values[0] != count {
+		t.Errorf("count = %v, want %d", values[0], count)
+	}
+	if values[1] != nil {
+		t.Errorf("name = %v, want nil", values[1])
+	}
+	if got, ok := values[2].(time.Time); !ok || !got.Equal(created) {
+		t.Errorf("created_at = %v, want %v", values[2], created)
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	sorts := []Sort{{Field: "count", Direction: Asc}}
+	valid := EncodeCursor(sorts, []interface{}{int64(3)}, 10)
+
+	tests := []struct {
+		name string
+		spec Spec
+	}{
+		{"not base64", Spec{Sort: sorts, Cursor: "!!"}},
+		{"not json", Spec{Sort: sorts, Cursor: "bm90IGpzb24"}},
+		{"other sort", Spec{Sort: []Sort{{Field: "count", Direction: Desc}}, Cursor: valid}},
+		{"fractional id", Spec{Sort: sorts, Cursor: "eyJzIjoiY291bnQ6YXNjIiwidiI6WzMsMS41XX0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := cursorSchema.DecodeCursor(tt.spec); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("err = %v, want ErrInvalidQuery", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+type Direction string
+
+const (
+	Asc  Direction = "asc"
+	Desc Direction = "desc"
+)
+
+type Operator string
+
+const (
+	OpEq   Operator = "eq"
+	OpNe   Operator = "ne"
+	OpGt   Operator = "gt"
+	OpGte  Operator = "gte"
+	OpLt   Operator = "lt"
+	OpLte  Operator = "lte"
+	OpLike Operator = "like"
+	OpIn   Operator = "in"
+)
+
+type FieldType int
+
+const (
+	String FieldType = iota
+	Int
+	Bool
+	Time
+)
+
+// Sort orders results by a single field
+type Sort struct {
+	Field     string
+	Direction Direction
+}
+
+// Filter restricts results to rows whose field matches Value.
+// Value holds an already typed value (a slice of them for OpIn).
+type Filter struct {
+	Field string
+	Op    Operator
+	Value interface{}
+}
+
+// Spec describes how a list should be filtered, sorted and paginated.
+// When Cursor is set it takes precedence over Offset.
+type Spec struct {
+	Limit   int
+	Offset  int
+	Cursor  string
+	Sort    []Sort
+	Filters []Filter
+}
+
+// Field declares what a client may do with a single field of a list
+type Field struct {
+	Type       FieldType
+	Sortable   bool
+	Filterable bool
+	// EqualityOnly limits filters to eq, ne and in, for fields that are stored
+	// encrypted and can only be compared through a blind index
+	EqualityOnly bool
+}
+
+// Schema is the whitelist of fields a list endpoint accepts
+type Schema struct {
+	Fields      map[string]Field
+	DefaultSort []Sort
+}
+
+// Page is one page of a list together with what is needed to fetch the next one
+type Page[T any] struct {
+	Items      []T
+	Total      int64
+	Limit      int
+	Offset     int
+	NextCursor string
+}
+
+// Normalize applies defaults to spec and rejects anything the schema does not allow
+func (s Schema) Normalize(spec *Spec) error {
+	switch {
+	case spec.Limit <= 0:
+		spec.Limit = DefaultLimit
+	case spec.Limit > MaxLimit:
+		spec.Limit = MaxLimit
+	}
+	if spec.Offset < 0 {
+		return fmt.Errorf("%w: offset must not be negative", ErrInvalidQuery)
+	}
+
+	if len(spec.Sort) == 0 {
+		spec.Sort = append([]Sort(nil), s.DefaultSort...)
+	}
+	for i, sort := range spec.Sort {
+		field, ok := s.Fields[sort.Field]
+		if !ok || !field.Sortable {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, sort.Field)
+		}
+		if sort.Direction == "" {
+			spec.Sort[i].Direction = Asc
+		}
+	}
+
+	for _, filter := range spec.Filters {
+		field, ok := s.Fields[filter.Field]
+		if !ok || !field.Filterable {
+			return fmt.Errorf("%w: cannot filter by %q", ErrInvalidQuery, filter.Field)
+		}
+		if filter.Op == OpLike && field.Type != String {
+			return fmt.Errorf("%w: %q does not support like", ErrInvalidQuery, filter.Field)
+		}
+		if field.EqualityOnly && filter.Op != OpEq && filter.Op != OpNe && filter.Op != OpIn {
+			return fmt.Errorf("%w: %q only supports eq, ne and in", ErrInvalidQuery, filter.Field)
+		}
+	}
+
+	return nil
+}
+
+// ParseValue converts a raw value into the type declared for field
+func (s Schema) ParseValue(field string, raw string) (interface{}, error) {
+	f, ok := s.Fields[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, field)
+	}
+
+	switch f.Type {
+	case Int:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q must be an integer", ErrInvalidQuery, field)
+		}
+		return v, nil
+	case Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q must be a boolean", ErrInvalidQuery, field)
+		}
+		return v, nil
+	case Time:
+		v, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q must be an RFC 3339 timestamp", ErrInvalidQuery, field)
+		}
+		return v, nil
+	default:
+		return raw, nil
+	}
+}
+
+// ParseFilter builds a typed filter from raw input; OpIn values are comma separated
+func (s Schema) ParseFilter(field string, op Operator, raw string) (Filter, error) {
+	switch op {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpLike:
+		v, err := s.ParseValue(field, raw)
+		if err != nil {
+			return Filter{}, err
+		}
+		return Filter{Field: field, Op: op, Value: v}, nil
+	case OpIn:
+		parts := strings.Split(raw, ",")
+		values := make([]interface{}, 0, len(parts))
+		for _, part := range parts {
+			v, err := s.ParseValue(field, strings.TrimSpace(part))
+			if err != nil {
+				return Filter{}, err
+			}
+			values = append(values, v)
+		}
+		return Filter{Field: field, Op: op, Value: values}, nil
+	default:
+		return Filter{}, fmt.Errorf("%w: unknown operator %q", ErrInvalidQuery, op)
+	}
+}
+
+// MapPage converts the items of a page while keeping its pagination details
+func MapPage[T any, U any](page *Page[T], fn func(T) U) *Page[U] {
+	items := make([]U, 0, len(page.Items))
+	for _, item := range page.Items {
+		items = append(items, fn(item))
+	}
+
+	return &Page[U]{
+		Items:      items,
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+	}
+}
@@ -0,0 +1,42 @@
+package transaction
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type consistencyKey struct{}
+
+type consistency struct {
+	wrote atomic.Bool
+}
+
+// WithReadYourWrites returns a context in which reads go to the primary database
+// as soon as anything has been written through it, so callers never read stale
+// data from a replica right after their own mutation.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(consistencyKey{}).(*consistency); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, consistencyKey{}, &consistency{})
+}
+
+// ForcePrimary returns a context whose reads always go to the primary database
+func ForcePrimary(ctx context.Context) context.Context {
+	c := &consistency{}
+	c.wrote.Store(true)
+	return context.WithValue(ctx, consistencyKey{}, c)
+}
+
+// MarkWritten records that ctx has been used for a write
+func MarkWritten(ctx context.Context) {
+	if c, ok := ctx.Value(consistencyKey{}).(*consistency); ok {
+		c.wrote.Store(true)
+	}
+}
+
+// ReadFromPrimary reports whether reads made with ctx must skip the replicas
+func ReadFromPrimary(ctx context.Context) bool {
+	c, ok := ctx.Value(consistencyKey{}).(*consistency)
+	return ok && c.wrote.Load()
+}
@@ -0,0 +1,50 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+)
+
+type hooksKey struct{}
+
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// AfterCommit runs fn once the transaction in ctx has committed, or right away
+// when ctx is not transactional. Hooks of rolled back transactions never run.
+func AfterCommit(ctx context.Context, fn func()) {
+	h, ok := ctx.Value(hooksKey{}).(*commitHooks)
+	if !ok {
+		fn()
+		return
+	}
+
+	h.mu.Lock()
+	h.fns = append(h.fns, fn)
+	h.mu.Unlock()
+}
+
+// InTransaction reports whether ctx belongs to a transaction that has not committed yet
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(hooksKey{}).(*commitHooks)
+	return ok
+}
+
+// WithCommitHooks prepares ctx to collect AfterCommit hooks. Manager implementations
+// call it when starting a transaction and call run after a successful commit.
+func WithCommitHooks(ctx context.Context) (context.Context, func()) {
+	h := &commitHooks{}
+	run := func() {
+		h.mu.Lock()
+		fns := h.fns
+		h.fns = nil
+		h.mu.Unlock()
+
+		for _, fn := range fns {
+			fn()
+		}
+	}
+	return context.WithValue(ctx, hooksKey{}, h), run
+}
@@ -0,0 +1,10 @@
+package transaction
+
+import "context"
+
+// Manager runs work atomically. Repositories called with the context handed to fn
+// take part in the same transaction; it is committed when fn returns nil and
+// rolled back otherwise.
+type Manager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
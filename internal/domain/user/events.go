@@ -0,0 +1,55 @@
+package user
+
+// User events reach other systems through the outbox, so their UserID is the
+// public ID of the user. They carry no email, which is only stored encrypted.
+const (
+	AggregateUser = "user"
+
+	EventRegistered      = "user.registered"
+	EventProfileUpdated  = "user.profile_updated"
+	EventPasswordChanged = "user.password_changed"
+	EventDeleted         = "user.deleted"
+	EventRestored        = "user.restored"
+)
+
+type Registered struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+func (e Registered) EventName() string     { return EventRegistered }
+func (e Registered) AggregateType() string { return AggregateUser }
+func (e Registered) AggregateID() string   { return e.UserID }
+
+type ProfileUpdated struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+func (e ProfileUpdated) EventName() string     { return EventProfileUpdated }
+func (e ProfileUpdated) AggregateType() string { return AggregateUser }
+func (e ProfileUpdated) AggregateID() string   { return e.UserID }
+
+type PasswordChanged struct {
+	UserID string `json:"user_id"`
+}
+
+func (e PasswordChanged) EventName() string     { return EventPasswordChanged }
+func (e PasswordChanged) AggregateType() string { return AggregateUser }
+func (e PasswordChanged) AggregateID() string   { return e.UserID }
+
+type Deleted struct {
+	UserID string `json:"user_id"`
+}
+
+func (e Deleted) EventName() string     { return EventDeleted }
+func (e Deleted) AggregateType() string { return AggregateUser }
+func (e Deleted) AggregateID() string   { return e.UserID }
+
+type Restored struct {
+	UserID string `json:"user_id"`
+}
+
+func (e Restored) EventName() string     { return EventRestored }
+func (e Restored) AggregateType() string { return AggregateUser }
+func (e Restored) AggregateID() string   { return e.UserID }
@@ -1,8 +1,30 @@
-package user
-
-type User struct {
-	ID       int64  `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"-"`
-}
\ No newline at end of file
+package user
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User is identified by ID internally and by PublicID everywhere outside the
+// app: in URLs, responses, tokens and events
+type User struct {
+	ID        int64      `json:"id"`
+	PublicID  string     `json:"public_id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Password  string     `json:"-"`
+	Role      string     `json:"role"`
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// NormalizeEmail returns the form emails are stored and looked up in
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
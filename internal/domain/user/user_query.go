@@ -0,0 +1,30 @@
+package user
+
+import "go-clean-v3/internal/domain/query"
+
+// ListSchema is the set of fields user lists can be sorted and filtered by.
+// The id field is the public ID. Emails are stored encrypted, so they can only
+// be matched exactly.
+var ListSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Type: query.String, Sortable: true, Filterable: true},
+		"name":       {Type: query.String, Sortable: true, Filterable: true},
+		"email":      {Type: query.String, Filterable: true, EqualityOnly: true},
+		"role":       {Type: query.String, Filterable: true},
+		"created_at": {Type: query.Time, Sortable: true, Filterable: true},
+	},
+	DefaultSort: []query.Sort{{Field: "created_at", Direction: query.Desc}},
+}
+
+// DeletedListSchema is ListSchema for users in the trash
+var DeletedListSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Type: query.String, Sortable: true, Filterable: true},
+		"name":       {Type: query.String, Sortable: true, Filterable: true},
+		"email":      {Type: query.String, Filterable: true, EqualityOnly: true},
+		"role":       {Type: query.String, Filterable: true},
+		"created_at": {Type: query.Time, Sortable: true, Filterable: true},
+		"deleted_at": {Type: query.Time, Sortable: true, Filterable: true},
+	},
+	DefaultSort: []query.Sort{{Field: "deleted_at", Direction: query.Desc}},
+}
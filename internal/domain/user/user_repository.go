@@ -1,18 +1,44 @@
-package user
-
-import "errors"
-
-var (
-	ErrUserNotFound = errors.New("user not found")
-	ErrUserExists   = errors.New("user already exists")
-	ErrInvalidUser  = errors.New("invalid user data")
-	ErrEmailExists  = errors.New("email already exists")
-)
-
-type UserRepositoryInterface interface {
-	Create(user *User) error
-	GetByID(id int64) (*User, error)
-	GetByEmail(email string) (*User, error)
-	Update(user *User) error
-	Delete(id int64) error
-}
+package user
+
+import (
+	"context"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/query"
+	"time"
+)
+
+var (
+	ErrUserNotFound    = domainErrors.NotFound("user_not_found", "user not found")
+	ErrUserExists      = domainErrors.Conflict("user_exists", "user already exists")
+	ErrInvalidUser     = domainErrors.Validation("invalid_user", "invalid user data")
+	ErrEmailExists     = domainErrors.Conflict("email_exists", "email already exists")
+	ErrInvalidPassword = domainErrors.Validation("invalid_password", "invalid password")
+	ErrInvalidRole     = domainErrors.Validation("invalid_role", "invalid role")
+	// ErrInvalidCredentials is returned for both unknown emails and wrong
+	// passwords, so logins cannot be used to find out who has an account
+	ErrInvalidCredentials = domainErrors.Unauthorized("invalid_credentials", "invalid email or password")
+)
+
+type UserRepositoryInterface interface {
+	// Create, Update and Restore return ErrEmailExists when the email belongs to
+	// another user that is not deleted
+	Create(ctx context.Context, user *User) error
+	GetByID(ctx context.Context, id int64) (*User, error)
+	GetByPublicID(ctx context.Context, publicID string) (*User, error)
+	// GetByEmail and the other reads may leave Password empty, unless ctx forces
+	// the primary with transaction.ForcePrimary
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	// Update saves user if its Version is still current and bumps the version,
+	// it returns errors.ErrConflict when the row was changed in the meantime. An
+	// empty Password keeps the stored one.
+	Update(ctx context.Context, user *User) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, spec query.Spec) (*query.Page[*User], error)
+
+	// ListDeleted returns soft-deleted users
+	ListDeleted(ctx context.Context, spec query.Spec) (*query.Page[*User], error)
+	// Restore clears the deletion mark of a soft-deleted user
+	Restore(ctx context.Context, id int64) error
+	// PurgeDeletedBefore permanently removes users deleted before the given time
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,30 @@
+package workspace
+
+import "context"
+
+type tenantKey struct{}
+
+type crossTenantKey struct{}
+
+// WithTenant scopes every repository call made with the returned context to workspaceID
+func WithTenant(ctx context.Context, workspaceID int64) context.Context {
+	return context.WithValue(ctx, tenantKey{}, workspaceID)
+}
+
+// TenantFromContext returns the workspace set by WithTenant
+func TenantFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(tenantKey{}).(int64)
+	return id, ok && id != 0
+}
+
+// CrossTenant lets tenant-scoped data be read and written without a tenant.
+// It is meant for system jobs; without it, tenant-scoped queries fail with ErrNoTenant.
+func CrossTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, crossTenantKey{}, true)
+}
+
+// IsCrossTenant reports whether ctx was marked with CrossTenant
+func IsCrossTenant(ctx context.Context) bool {
+	cross, _ := ctx.Value(crossTenantKey{}).(bool)
+	return cross
+}
@@ -0,0 +1,28 @@
+package workspace
+
+import "time"
+
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+// Workspace is a tenant. Users belong to workspaces through memberships.
+// Like users, workspaces are known by PublicID outside the app.
+type Workspace struct {
+	ID        int64     `json:"id"`
+	PublicID  string    `json:"public_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Membership grants a user a role in a workspace. UserPublicID is only filled
+// in by ListMembers.
+type Membership struct {
+	ID           int64     `json:"id"`
+	WorkspaceID  int64     `json:"workspace_id"`
+	UserID       int64     `json:"user_id"`
+	UserPublicID string    `json:"user_public_id"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
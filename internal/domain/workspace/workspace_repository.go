@@ -0,0 +1,42 @@
+package workspace
+
+import (
+	"context"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/query"
+)
+
+var (
+	ErrWorkspaceNotFound = domainErrors.NotFound("workspace_not_found", "workspace not found")
+	ErrMemberNotFound    = domainErrors.NotFound("member_not_found", "member not found")
+	ErrAlreadyMember     = domainErrors.Conflict("already_member", "user is already a member of the workspace")
+	ErrNoTenant          = domainErrors.Validation("workspace_required", "no workspace selected")
+	ErrInvalidRole       = domainErrors.Validation("invalid_workspace_role", "invalid workspace role")
+)
+
+// WorkspaceRepositoryInterface stores workspaces and their memberships.
+// Membership methods are scoped to the tenant in ctx, see WithTenant.
+type WorkspaceRepositoryInterface interface {
+	Create(ctx context.Context, w *Workspace) error
+	GetByID(ctx context.Context, id int64) (*Workspace, error)
+	GetByPublicID(ctx context.Context, publicID string) (*Workspace, error)
+	// ListForUser returns every workspace userID is a member of
+	ListForUser(ctx context.Context, userID int64) ([]*Workspace, error)
+
+	// AddMember returns ErrAlreadyMember when the user is in the workspace already
+	AddMember(ctx context.Context, m *Membership) error
+	GetMember(ctx context.Context, userID int64) (*Membership, error)
+	ListMembers(ctx context.Context, spec query.Spec) (*query.Page[*Membership], error)
+}
+
+// MemberListSchema is the set of fields member lists can be sorted and filtered by.
+// user_id is the public ID of the user.
+var MemberListSchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Type: query.Int, Sortable: true, Filterable: true},
+		"user_id":    {Type: query.String, Filterable: true, EqualityOnly: true},
+		"role":       {Type: query.String, Filterable: true},
+		"created_at": {Type: query.Time, Sortable: true, Filterable: true},
+	},
+	DefaultSort: []query.Sort{{Field: "created_at", Direction: query.Asc}},
+}
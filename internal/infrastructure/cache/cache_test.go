@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testCache checks the behaviour every Cache shares. expire makes entries set
+// with a one second TTL run out.
+func testCache(t *testing.T, c Cache, expire func()) {
+	ctx := context.Background()
+
+	t.Run("miss", func(t *testing.T) {
+		if _, ok, err := c.Get(ctx, "missing"); err != nil || ok {
+			t.Fatalf("Get = ok %v, err %v; want a miss", ok, err)
+		}
+	})
+
+	t.Run("set and get", func(t *testing.T) {
+		if err := c.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Set(ctx, "a", []byte("2"), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		value, ok, err := c.Get(ctx, "a")
+		if err != nil || !ok || string(value) != "2" {
+			t.Fatalf("Get = %q, ok %v, err %v; want \"2\"", value, ok, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		for _, key := range []string{"b", "c"} {
+			if err := c.Set(ctx, key, []byte(key), time.Minute); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := c.Delete(ctx, "b", "c", "missing"); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Delete(ctx); err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range []string{"b", "c"} {
+			if _, ok, _ := c.Get(ctx, key); ok {
+				t.Errorf("%s is still cached", key)
+			}
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		if err := c.Set(ctx, "d", []byte("d"), time.Second); err != nil {
+			t.Fatal(err)
+		}
+		expire()
+		if _, ok, _ := c.Get(ctx, "d"); ok {
+			t.Error("expired entry is still cached")
+		}
+	})
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(10).(*memoryCache)
+	testCache(t, c, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, elem := range c.entries {
+			elem.Value.(*memoryEntry).expiresAt = time.Now().Add(-time.Second)
+		}
+	})
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	_ = c.Set(ctx, "a", []byte("a"), time.Minute)
+	_ = c.Set(ctx, "b", []byte("b"), time.Minute)
+	// Reading a makes b the least recently used entry
+	_, _, _ = c.Get(ctx, "a")
+	_ = c.Set(ctx, "c", []byte("c"), time.Minute)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := c.Get(ctx, key); ok != want {
+			t.Errorf("%s cached = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	c := NewRedisCache(client, "app:")
+	testCache(t, c, func() { server.FastForward(2 * time.Second) })
+
+	t.Run("prefix", func(t *testing.T) {
+		if err := c.Set(context.Background(), "e", []byte("e"), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if !server.Exists("app:e") {
+			t.Error("key is not prefixed")
+		}
+	})
+
+	t.Run("server down", func(t *testing.T) {
+		server.Close()
+		if _, _, err := c.Get(context.Background(), "a"); err == nil {
+			t.Error("Get succeeded without a server")
+		}
+	})
+}
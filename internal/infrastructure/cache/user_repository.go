@@ -0,0 +1,290 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/transaction"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/infrastructure/encryption"
+	"go-clean-v3/pkg/logger"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// cachedUser is the cached form of user.User. The email is encrypted like in the
+// database, and the password hash is left out, so a leaked cache holds neither.
+type cachedUser struct {
+	ID         int64      `json:"id"`
+	PublicID   string     `json:"public_id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	EmailIndex string     `json:"email_index"`
+	Role       string     `json:"role"`
+	Version    int64      `json:"version"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// userRepository caches GetByID, GetByPublicID and GetByEmail of another
+// repository. Users are cached by ID; the email and public ID keys only point at
+// the ID, so an email change can never serve a stale user. Email keys use the
+// blind index of the email, which keeps addresses out of key listings.
+//
+// Users read through the cache have no Password. Reads inside a transaction, or
+// with a context that forces the primary, bypass the cache: the former may see
+// rows that are not committed yet, the latter are how credential checks get the
+// password hash.
+type userRepository struct {
+	user.UserRepositoryInterface
+	cache      Cache
+	ttl        time.Duration
+	encryption *encryption.Service
+	group      singleflight.Group
+}
+
+// NewUserRepository wraps repo with a read-through cache that is invalidated on every write
+func NewUserRepository(repo user.UserRepositoryInterface, cache Cache, ttl time.Duration, encryption *encryption.Service) user.UserRepositoryInterface {
+	return &userRepository{
+		UserRepositoryInterface: repo,
+		cache:                   cache,
+		ttl:                     ttl,
+		encryption:              encryption,
+	}
+}
+
+func userIDKey(id int64) string {
+	return "user:id:" + strconv.FormatInt(id, 10)
+}
+
+func userEmailKey(emailIndex string) string {
+	return "user:email:" + emailIndex
+}
+
+func userPublicIDKey(publicID string) string {
+	return "user:public_id:" + publicID
+}
+
+// GetByID implements user.UserRepositoryInterface.
+func (r *userRepository) GetByID(ctx context.Context, id int64) (*user.User, error) {
+	if bypass(ctx) {
+		return r.UserRepositoryInterface.GetByID(ctx, id)
+	}
+
+	key := userIDKey(id)
+	if u, _, ok := r.getCached(ctx, key); ok {
+		return u, nil
+	}
+
+	return r.load(ctx, key, func(ctx context.Context) (*user.User, error) {
+		return r.UserRepositoryInterface.GetByID(ctx, id)
+	})
+}
+
+// GetByEmail implements user.UserRepositoryInterface.
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	if bypass(ctx) {
+		return r.UserRepositoryInterface.GetByEmail(ctx, email)
+	}
+
+	emailIndex := r.encryption.BlindIndex(email)
+	key := userEmailKey(emailIndex)
+	if id, ok := r.cachedID(ctx, key); ok {
+		if u, cachedIndex, ok := r.getCached(ctx, userIDKey(id)); ok && cachedIndex == emailIndex {
+			return u, nil
+		}
+	}
+
+	return r.load(ctx, key, func(ctx context.Context) (*user.User, error) {
+		return r.UserRepositoryInterface.GetByEmail(ctx, email)
+	})
+}
+
+// GetByPublicID implements user.UserRepositoryInterface.
+func (r *userRepository) GetByPublicID(ctx context.Context, publicID string) (*user.User, error) {
+	if bypass(ctx) {
+		return r.UserRepositoryInterface.GetByPublicID(ctx, publicID)
+	}
+
+	key := userPublicIDKey(publicID)
+	if id, ok := r.cachedID(ctx, key); ok {
+		if u, _, ok := r.getCached(ctx, userIDKey(id)); ok && u.PublicID == publicID {
+			return u, nil
+		}
+	}
+
+	return r.load(ctx, key, func(ctx context.Context) (*user.User, error) {
+		return r.UserRepositoryInterface.GetByPublicID(ctx, publicID)
+	})
+}
+
+// load reads a user that is not cached with fetch and caches it. Concurrent
+// misses for the same key share a single database read, which runs without the
+// cancellation of the caller that started it, so that caller going away does
+// not fail the others. Each caller stops waiting when its own ctx is done.
+func (r *userRepository) load(ctx context.Context, key string, fetch func(ctx context.Context) (*user.User, error)) (*user.User, error) {
+	loaded := r.group.DoChan(key, func() (interface{}, error) {
+		// Fill the cache from the primary so lagging replicas cannot poison it
+		ctx := transaction.ForcePrimary(context.WithoutCancel(ctx))
+		u, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.setCached(ctx, u)
+		return u, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-loaded:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		u := *result.Val.(*user.User)
+		u.Password = ""
+		return &u, nil
+	}
+}
+
+// Update implements user.UserRepositoryInterface.
+func (r *userRepository) Update(ctx context.Context, usr *user.User) error {
+	if err := r.UserRepositoryInterface.Update(ctx, usr); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, usr.ID)
+	return nil
+}
+
+// Delete implements user.UserRepositoryInterface.
+func (r *userRepository) Delete(ctx context.Context, id int64) error {
+	if err := r.UserRepositoryInterface.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// Restore implements user.UserRepositoryInterface.
+func (r *userRepository) Restore(ctx context.Context, id int64) error {
+	if err := r.UserRepositoryInterface.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// PurgeDeletedBefore implements user.UserRepositoryInterface.
+// Purged users were already invalidated when they were deleted.
+func (r *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return r.UserRepositoryInterface.PurgeDeletedBefore(ctx, before)
+}
+
+// List implements user.UserRepositoryInterface. Lists are never cached.
+func (r *userRepository) List(ctx context.Context, spec query.Spec) (*query.Page[*user.User], error) {
+	return r.UserRepositoryInterface.List(ctx, spec)
+}
+
+// invalidate drops the cached user now and again once the surrounding transaction
+// commits, so readers racing with the commit cannot leave an old copy behind.
+// Email keys are left to expire; GetByEmail checks them against the user.
+func (r *userRepository) invalidate(ctx context.Context, id int64) {
+	drop := func() {
+		if err := r.cache.Delete(context.WithoutCancel(ctx), userIDKey(id)); err != nil {
+			logger.ErrorContext(ctx, "[CachedUserRepository-invalidate-1] Cache delete failed", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	drop()
+	transaction.AfterCommit(ctx, drop)
+}
+
+// bypass reports whether reads made with ctx must not use the cache
+func bypass(ctx context.Context) bool {
+	return transaction.InTransaction(ctx) || transaction.ReadFromPrimary(ctx)
+}
+
+// cachedID returns the user ID an email or public ID key points at
+func (r *userRepository) cachedID(ctx context.Context, key string) (int64, bool) {
+	raw, ok, err := r.cache.Get(ctx, key)
+	if err != nil || !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	return id, err == nil
+}
+
+// getCached returns the user cached under key and the blind index of its email
+func (r *userRepository) getCached(ctx context.Context, key string) (*user.User, string, bool) {
+	raw, ok, err := r.cache.Get(ctx, key)
+	if err != nil {
+		logger.ErrorContext(ctx, "[CachedUserRepository-getCached-1] Cache read failed", map[string]interface{}{"error": err.Error()})
+		return nil, "", false
+	}
+	if !ok {
+		return nil, "", false
+	}
+
+	// Entries cached before users had public IDs or encrypted emails are treated
+	// as misses, and so are emails sealed with a key that has since been removed
+	var c cachedUser
+	if err := json.Unmarshal(raw, &c); err != nil || c.PublicID == "" || c.EmailIndex == "" {
+		return nil, "", false
+	}
+	email, err := r.encryption.Decrypt(c.Email)
+	if err != nil {
+		return nil, "", false
+	}
+
+	return &user.User{
+		ID:        c.ID,
+		PublicID:  c.PublicID,
+		Name:      c.Name,
+		Email:     string(email),
+		Role:      c.Role,
+		Version:   c.Version,
+		CreatedAt: c.CreatedAt,
+		DeletedAt: c.DeletedAt,
+	}, c.EmailIndex, true
+}
+
+func (r *userRepository) setCached(ctx context.Context, u *user.User) {
+	email, err := r.encryption.Encrypt([]byte(u.Email))
+	if err != nil {
+		logger.ErrorContext(ctx, "[CachedUserRepository-setCached-4] Email encryption failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	emailIndex := r.encryption.BlindIndex(u.Email)
+
+	raw, err := json.Marshal(cachedUser{
+		ID:         u.ID,
+		PublicID:   u.PublicID,
+		Name:       u.Name,
+		Email:      email,
+		EmailIndex: emailIndex,
+		Role:       u.Role,
+		Version:    u.Version,
+		CreatedAt:  u.CreatedAt,
+		DeletedAt:  u.DeletedAt,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := r.cache.Set(ctx, userIDKey(u.ID), raw, r.ttl); err != nil {
+		logger.ErrorContext(ctx, "[CachedUserRepository-setCached-1] Cache write failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	id := []byte(strconv.FormatInt(u.ID, 10))
+	if err := r.cache.Set(ctx, userEmailKey(emailIndex), id, r.ttl); err != nil {
+		logger.ErrorContext(ctx, "[CachedUserRepository-setCached-2] Cache write failed", map[string]interface{}{"error": err.Error()})
+	}
+	if err := r.cache.Set(ctx, userPublicIDKey(u.PublicID), id, r.ttl); err != nil {
+		logger.ErrorContext(ctx, "[CachedUserRepository-setCached-3] Cache write failed", map[string]interface{}{"error": err.Error()})
+	}
+}
@@ -0,0 +1,356 @@
+package cache
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"go-clean-v3/internal/domain/transaction"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/infrastructure/encryption"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubUsers serves a fixed set of users and counts the reads that reach it
+type stubUsers struct {
+	user.UserRepositoryInterface
+
+	mu    sync.Mutex
+	users map[int64]user.User
+	reads atomic.Int64
+	// gate, when set, holds every read until it is closed or the read's ctx is
+	// done
+	gate chan struct{}
+}
+
+func newStubUsers(users ...user.User) *stubUsers {
+	s := &stubUsers{users: map[int64]user.User{}}
+	for _, u := range users {
+		s.users[u.ID] = u
+	}
+	return s
+}
+
+func (s *stubUsers) find(ctx context.Context, match func(user.User) bool) (*user.User, error) {
+	s.reads.Add(1)
+	if s.gate != nil {
+		select {
+		case <-s.gate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if match(u) {
+			return &u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (s *stubUsers) GetByID(ctx context.Context, id int64) (*user.User, error) {
+	return s.find(ctx, func(u user.User) bool { return u.ID == id })
+}
+
+func (s *stubUsers) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	return s.find(ctx, func(u user.User) bool { return u.Email == email })
+}
+
+func (s *stubUsers) GetByPublicID(ctx context.Context, publicID string) (*user.User, error) {
+	return s.find(ctx, func(u user.User) bool { return u.PublicID == publicID })
+}
+
+func (s *stubUsers) Update(ctx context.Context, u *user.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u.Version++
+	s.users[u.ID] = *u
+	return nil
+}
+
+var testEncryption = func() *encryption.Service {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	s, err := encryption.NewService("test:"+key, "test", key)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}()
+
+var alice = user.User{ID: 1, PublicID: "0b6e5c36-4a77-4f2a-9f52-0c1f4fd5a001", Name: "Alice", Email: "alice@example.com", Password: "hash", Role: user.RoleUser, Version: 1}
+
+func TestUserRepositoryCachesReads(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubUsers(alice)
+	repo := NewUserRepository(stub, NewMemoryCache(100), time.Minute, testEncryption)
+
+	for i := 0; i < 3; i++ {
+		u, err := repo.GetByID(ctx, alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Name != alice.Name || u.Email != alice.Email || u.PublicID != alice.PublicID {
+			t.Fatalf("GetByID = %+v, want %+v", u, alice)
+		}
+	}
+	if _, err := repo.GetByPublicID(ctx, alice.PublicID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByEmail(ctx, alice.Email); err != nil {
+		t.Fatal(err)
+	}
+
+	// The public ID and email keys written by the first read point at the cached user
+	if reads := stub.reads.Load(); reads != 1 {
+		t.Errorf("repository reads = %d, want 1", reads)
+	}
+}
+
+func TestUserRepositoryKeepsPasswordsOutOfCache(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubUsers(alice)
+	cache := NewMemoryCache(100)
+	repo := NewUserRepository(stub, cache, time.Minute, testEncryption)
+
+	// Neither the read that fills the cache nor the ones served from it return the hash
+	for i := 0; i < 2; i++ {
+		u, err := repo.GetByEmail(ctx, alice.Email)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Password != "" {
+			t.Errorf("read %d returned the password hash", i+1)
+		}
+	}
+
+	raw, ok, _ := cache.Get(ctx, userIDKey(alice.ID))
+	if !ok {
+		t.Fatal("user is not cached")
+	}
+	if strings.Contains(string(raw), alice.Password) {
+		t.Errorf("cached user %s holds the password hash", raw)
+	}
+
+	// Credential checks force the primary and get the hash from the repository
+	u, err := repo.GetByEmail(transaction.ForcePrimary(ctx), alice.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Password != alice.Password {
+		t.Errorf("Password = %q when forcing the primary, want %q", u.Password, alice.Password)
+	}
+	if reads := stub.reads.Load(); reads != 2 {
+		t.Errorf("repository reads = %d, want 2", reads)
+	}
+}
+
+func TestUserRepositoryKeysEmailsByBlindIndex(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubUsers(alice)
+	cache := NewMemoryCache(100)
+	repo := NewUserRepository(stub, cache, time.Minute, testEncryption)
+
+	if _, err := repo.GetByEmail(ctx, alice.Email); err != nil {
+		t.Fatal(err)
+	}
+	raw, ok, _ := cache.Get(ctx, userIDKey(alice.ID))
+	if !ok {
+		t.Fatal("user is not cached")
+	}
+	if strings.Contains(string(raw), alice.Email) {
+		t.Errorf("cached user %s holds the email in plaintext", raw)
+	}
+
+	// Cached reads still return the plaintext email
+	u, err := repo.GetByEmail(ctx, alice.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Email != alice.Email {
+		t.Errorf("Email = %q from the cache, want %q", u.Email, alice.Email)
+	}
+	if reads := stub.reads.Load(); reads != 1 {
+		t.Errorf("repository reads = %d, want 1", reads)
+	}
+	if _, ok, _ := cache.Get(ctx, userEmailKey(testEncryption.BlindIndex(alice.Email))); !ok {
+		t.Error("email key is not the blind index of the email")
+	}
+	if _, ok, _ := cache.Get(ctx, "user:email:"+alice.Email); ok {
+		t.Error("email is used as a cache key in plaintext")
+	}
+}
+
+func TestUserRepositoryMiss(t *testing.T) {
+	stub := newStubUsers()
+	repo := NewUserRepository(stub, NewMemoryCache(100), time.Minute, testEncryption)
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetByID(context.Background(), 42); err != user.ErrUserNotFound {
+			t.Fatalf("err = %v, want ErrUserNotFound", err)
+		}
+	}
+	// Missing users are not cached
+	if reads := stub.reads.Load(); reads != 2 {
+		t.Errorf("repository reads = %d, want 2", reads)
+	}
+}
+
+func TestUserRepositoryInvalidatesOnUpdate(t *testing.T) {
+	ctx := context.Background()
+	stub := newStubUsers(alice)
+	repo := NewUserRepository(stub, NewMemoryCache(100), time.Minute, testEncryption)
+
+	if _, err := repo.GetByID(ctx, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	renamed := alice
+	renamed.Name = "Alicia"
+	if err := repo.Update(ctx, &renamed); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := repo.GetByID(ctx, alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "Alicia" {
+		t.Errorf("Name = %q after update, want Alicia", u.Name)
+	}
+}
+
+func TestUserRepositoryInvalidatesAfterCommit(t *testing.T) {
+	stub := newStubUsers(alice)
+	cache := NewMemoryCache(100)
+	repo := NewUserRepository(stub, cache, time.Minute, testEncryption)
+
+	txCtx, commit := transaction.WithCommitHooks(context.Background())
+	renamed := alice
+	renamed.Name = "Alicia"
+	if err := repo.Update(txCtx, &renamed); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reader racing with the commit caches the row it sees
+	if _, err := repo.GetByID(context.Background(), alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	commit()
+
+	if _, ok, _ := cache.Get(context.Background(), userIDKey(alice.ID)); ok {
+		t.Error("user is still cached after the commit")
+	}
+}
+
+func TestUserRepositoryBypassesCacheInTransaction(t *testing.T) {
+	stub := newStubUsers(alice)
+	cache := NewMemoryCache(100)
+	repo := NewUserRepository(stub, cache, time.Minute, testEncryption)
+
+	txCtx, _ := transaction.WithCommitHooks(context.Background())
+	for i := 0; i < 2; i++ {
+		if _, err := repo.GetByID(txCtx, alice.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.GetByPublicID(txCtx, alice.PublicID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.GetByEmail(txCtx, alice.Email); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if reads := stub.reads.Load(); reads != 6 {
+		t.Errorf("repository reads = %d, want 6", reads)
+	}
+	if _, ok, _ := cache.Get(context.Background(), userIDKey(alice.ID)); ok {
+		t.Error("a read inside a transaction filled the cache")
+	}
+}
+
+func TestUserRepositorySharesConcurrentMisses(t *testing.T) {
+	stub := newStubUsers(alice)
+	stub.gate = make(chan struct{})
+	repo := NewUserRepository(stub, NewMemoryCache(100), time.Minute, testEncryption)
+
+	const readers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, readers)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.GetByID(context.Background(), alice.ID)
+			errs <- err
+		}()
+	}
+
+	// Give every reader time to join the first one before it is let through
+	time.Sleep(50 * time.Millisecond)
+	close(stub.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reads := stub.reads.Load(); reads != 1 {
+		t.Errorf("repository reads = %d, want 1", reads)
+	}
+}
+
+func TestUserRepositorySharedMissOutlivesCancelledCaller(t *testing.T) {
+	stub := newStubUsers(alice)
+	stub.gate = make(chan struct{})
+	repo := NewUserRepository(stub, NewMemoryCache(100), time.Minute, testEncryption)
+
+	// The first caller starts the shared read and goes away before it finishes
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := repo.GetByID(ctx, alice.ID)
+		first <- err
+	}()
+	for stub.reads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := repo.GetByID(context.Background(), alice.ID)
+		second <- err
+	}()
+
+	cancel()
+	select {
+	case err := <-first:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("first err = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the cancelled caller kept waiting for the shared read")
+	}
+
+	// Give the second caller time to join the shared read
+	time.Sleep(50 * time.Millisecond)
+	close(stub.gate)
+	select {
+	case err := <-second:
+		if err != nil {
+			t.Errorf("second err = %v, the first caller's cancellation failed the shared read", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the second caller did not get the user")
+	}
+	if reads := stub.reads.Load(); reads != 1 {
+		t.Errorf("repository reads = %d, want 1", reads)
+	}
+}
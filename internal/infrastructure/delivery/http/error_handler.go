@@ -0,0 +1,157 @@
+package http
+
+import (
+	"errors"
+	"go-clean-v3/internal/config"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/pkg/logger"
+	"go-clean-v3/pkg/requestid"
+	"go-clean-v3/pkg/response"
+	"go-clean-v3/pkg/tracing"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errorMapping gives a domain error that is not an AppError its status and code
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var errorMappings = []errorMapping{
+	{query.ErrInvalidQuery, http.StatusBadRequest, "invalid_query"},
+	{domainErrors.ErrDuplicate, http.StatusConflict, "duplicate"},
+	{domainErrors.ErrReference, http.StatusConflict, "invalid_reference"},
+	{domainErrors.ErrMissingValue, http.StatusBadRequest, "missing_value"},
+	{domainErrors.ErrDeadlock, http.StatusServiceUnavailable, "deadlock"},
+}
+
+var kindStatus = map[domainErrors.Kind]int{
+	domainErrors.KindNotFound:     http.StatusNotFound,
+	domainErrors.KindConflict:     http.StatusConflict,
+	domainErrors.KindValidation:   http.StatusBadRequest,
+	domainErrors.KindUnauthorized: http.StatusUnauthorized,
+	domainErrors.KindForbidden:    http.StatusForbidden,
+	domainErrors.KindInternal:     http.StatusInternalServerError,
+
+	domainErrors.KindPreconditionFailed: http.StatusPreconditionFailed,
+}
+
+// findMapping returns how err is reported. AppErrors take precedence, as they
+// may wrap the errors in errorMappings.
+func findMapping(err error) (errorMapping, bool) {
+	var appErr *domainErrors.AppError
+	if errors.As(err, &appErr) {
+		return errorMapping{err: appErr, status: kindStatus[appErr.Kind], code: appErr.Code}, true
+	}
+
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+// statusCode is the code of errors that only have a status, e.g. not_found
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// NewErrorHandler returns the echo.HTTPErrorHandler that writes every error as
+// RFC 7807 problem details. Outside production the internal cause is included.
+func NewErrorHandler(cfg *config.Config) echo.HTTPErrorHandler {
+	production := cfg.Environment == "production"
+
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		p := problemFor(err)
+		p.Instance = c.Request().URL.Path
+		p.RequestID = requestID(c)
+		p.TraceID = tracing.TraceID(c.Request().Context())
+		if !production {
+			p.Cause = err.Error()
+		}
+
+		trace.SpanFromContext(c.Request().Context()).RecordError(err)
+		if p.Status >= http.StatusInternalServerError {
+			logger.ErrorContext(c.Request().Context(), "[ErrorHandler-1] Request failed", map[string]interface{}{
+				"error":  err.Error(),
+				"method": c.Request().Method,
+				"path":   c.Request().URL.Path,
+			})
+		}
+
+		if err := response.WriteProblem(c, p); err != nil {
+			logger.ErrorContext(c.Request().Context(), "[ErrorHandler-2] Writing the error failed", map[string]interface{}{"error": err.Error()})
+		}
+	}
+}
+
+func problemFor(err error) *response.Problem {
+	var httpErr *response.HTTPError
+	if errors.As(err, &httpErr) {
+		p := response.NewProblem(httpErr.Status, statusCode(httpErr.Status), httpErr.Message)
+		if m, ok := findMapping(httpErr.Err); ok {
+			p.Code = m.code
+			p.Errors = details(m.err)
+		}
+		return p
+	}
+
+	var echoErr *echo.HTTPError
+	if errors.As(err, &echoErr) {
+		p := response.NewProblem(echoErr.Code, statusCode(echoErr.Code), "")
+		if message, ok := echoErr.Message.(string); ok {
+			p.Detail = message
+		} else {
+			p.Errors = echoErr.Message
+		}
+		return p
+	}
+
+	m, ok := findMapping(err)
+	if !ok {
+		m = errorMapping{err: domainErrors.ErrInternal, status: http.StatusInternalServerError, code: domainErrors.ErrInternal.Code}
+	}
+	if m.status >= http.StatusInternalServerError {
+		return response.NewProblem(m.status, m.code, "An internal error occurred")
+	}
+
+	// Only the mapped error's own message, the errors it wraps may carry internals
+	p := response.NewProblem(m.status, m.code, m.err.Error())
+	if appErr, ok := m.err.(*domainErrors.AppError); ok {
+		p.Detail = appErr.Message
+	}
+	p.Errors = details(m.err)
+	return p
+}
+
+// details returns the field errors of an AppError, or nil
+func details(err error) interface{} {
+	if appErr, ok := err.(*domainErrors.AppError); ok && len(appErr.Details) > 0 {
+		return appErr.Details
+	}
+	return nil
+}
+
+// requestID identifies the request in the logs. It is generated here for
+// requests that did not pass the RequestID middleware.
+func requestID(c echo.Context) string {
+	if id := requestid.FromContext(c.Request().Context()); id != "" {
+		return id
+	}
+
+	id := requestid.New()
+	c.Response().Header().Set(requestid.Header, id)
+	c.SetRequest(c.Request().WithContext(requestid.NewContext(c.Request().Context(), id)))
+	return id
+}
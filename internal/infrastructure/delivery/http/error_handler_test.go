@@ -0,0 +1,37 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/domain/workspace"
+	"net/http"
+	"testing"
+)
+
+func TestProblemFor(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		status int
+		code   string
+	}{
+		"not found":            {fmt.Errorf("get profile: %w", user.ErrUserNotFound), http.StatusNotFound, "user_not_found"},
+		"email taken":          {user.ErrEmailExists, http.StatusConflict, "email_exists"},
+		"concurrent change":    {domainErrors.ErrConflict, http.StatusConflict, "version_conflict"},
+		"stale if-match":       {domainErrors.ErrPreconditionFailed.Wrap(domainErrors.ErrConflict), http.StatusPreconditionFailed, "precondition_failed"},
+		"wrong password":       {user.ErrInvalidPassword, http.StatusBadRequest, "invalid_password"},
+		"already member":       {fmt.Errorf("%w: %w", workspace.ErrAlreadyMember, domainErrors.ErrDuplicate), http.StatusConflict, "already_member"},
+		"no workspace":         {workspace.ErrNoTenant, http.StatusBadRequest, "workspace_required"},
+		"workspace role":       {workspace.ErrInvalidRole, http.StatusBadRequest, "invalid_workspace_role"},
+		"database unavailable": {errors.New("dial tcp: connection refused"), http.StatusInternalServerError, "internal"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := problemFor(tt.err)
+			if p.Status != tt.status || p.Code != tt.code {
+				t.Errorf("problem = %d %s, want %d %s", p.Status, p.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
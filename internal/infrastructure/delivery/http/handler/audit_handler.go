@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	domainAudit "go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/usecase/audit"
+	"go-clean-v3/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuditHandler struct {
+	auditUsecase *audit.AuditUsecase
+}
+
+func NewAuditHandler(auditUsecase *audit.AuditUsecase) *AuditHandler {
+	return &AuditHandler{auditUsecase: auditUsecase}
+}
+
+// List returns a filterable page of audit entries (admin only)
+func (h *AuditHandler) List(c echo.Context) error {
+	spec, err := parseQuerySpec(c, domainAudit.ListSchema)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.auditUsecase.List(c.Request().Context(), spec)
+	if err != nil {
+		if errors.Is(err, query.ErrInvalidQuery) {
+			return response.Error(c, http.StatusBadRequest, err.Error(), err)
+		}
+		return err
+	}
+
+	return paginated(c, page)
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"errors"
+	"go-clean-v3/internal/infrastructure/delivery/http/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bind decodes the request into req and validates it. Validation errors are
+// translated to the language the client asked for in Accept-Language.
+func bind(c echo.Context, req interface{}) error {
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(req); err != nil {
+		var validationErr *middleware.ValidationError
+		if errors.As(err, &validationErr) {
+			return validationErr.Translate(c.Request().Header.Get("Accept-Language"))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"database/sql"
+	"go-clean-v3/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DBStatsFunc returns the statistics of every database connection pool by name
+type DBStatsFunc func() map[string]sql.DBStats
+
+type DBStatsResponse struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+type DBHandler struct {
+	stats DBStatsFunc
+}
+
+func NewDBHandler(stats DBStatsFunc) *DBHandler {
+	return &DBHandler{stats: stats}
+}
+
+// Stats returns connection pool statistics of the primary and replica databases (admin only)
+func (h *DBHandler) Stats(c echo.Context) error {
+	pools := map[string]DBStatsResponse{}
+	for name, s := range h.stats() {
+		pools[name] = DBStatsResponse{
+			MaxOpenConnections: s.MaxOpenConnections,
+			OpenConnections:    s.OpenConnections,
+			InUse:              s.InUse,
+			Idle:               s.Idle,
+			WaitCount:          s.WaitCount,
+			WaitDurationMs:     s.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      s.MaxIdleClosed,
+			MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  s.MaxLifetimeClosed,
+		}
+	}
+
+	return response.JSON(c, http.StatusOK, pools)
+}
@@ -1,10 +1,15 @@
-package handler
-
-// Handlers groups all HTTP handlers for easy routing
-type Handlers struct {
-    UserHandler *UserHandler
-    AuthHandler *AuthHandler
-    // Add more here as you create them:
-    // TodoHandler      *TodoHandler
-    // ProductHandler   *ProductHandler
+package handler
+
+// Handlers groups all HTTP handlers for easy routing
+type Handlers struct {
+    UserHandler *UserHandler
+    AuthHandler *AuthHandler
+    TrashHandler *TrashHandler
+    AuditHandler *AuditHandler
+    DBHandler    *DBHandler
+    WorkspaceHandler *WorkspaceHandler
+    HealthHandler    *HealthHandler
+    // Add more here as you create them:
+    // TodoHandler      *TodoHandler
+    // ProductHandler   *ProductHandler
 }
\ No newline at end of file
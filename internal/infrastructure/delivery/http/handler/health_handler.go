@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-clean-v3/pkg/response"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HealthCheck reports whether a dependency is usable; nil means it is
+type HealthCheck func(ctx context.Context) error
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// HealthHandler serves the liveness and readiness probes. Readiness runs every
+// registered check concurrently, each bounded by timeout, and fails for good
+// once Drain is called.
+type HealthHandler struct {
+	timeout    time.Duration
+	showErrors bool
+
+	mu       sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// NewHealthHandler returns a handler without checks. Check errors are only
+// included in the response when showErrors is set, as they may reveal internals.
+func NewHealthHandler(timeout time.Duration, showErrors bool) (*HealthHandler, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("health check timeout must be positive, got %s", timeout)
+	}
+	return &HealthHandler{timeout: timeout, showErrors: showErrors}, nil
+}
+
+// Register adds a readiness check reported under name
+func (h *HealthHandler) Register(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes readiness fail from now on, so no new traffic is routed to a
+// server that is shutting down
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Live reports that the process is up; it never looks at dependencies
+func (h *HealthHandler) Live(c echo.Context) error {
+	return response.JSON(c, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Ready reports whether the server can take traffic, with the result of every check
+func (h *HealthHandler) Ready(c echo.Context) error {
+	if h.draining.Load() {
+		return response.JSON(c, http.StatusServiceUnavailable, ReadinessResponse{
+			Status: "draining",
+			Checks: map[string]CheckResult{},
+		})
+	}
+
+	h.mu.RLock()
+	checks := append([]namedCheck(nil), h.checks...)
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func(i int, nc namedCheck) {
+			defer wg.Done()
+			results[i] = h.run(c.Request().Context(), nc.check)
+		}(i, nc)
+	}
+	wg.Wait()
+
+	resp := ReadinessResponse{Status: "ok", Checks: make(map[string]CheckResult, len(checks))}
+	for i, nc := range checks {
+		resp.Checks[nc.name] = results[i]
+		if results[i].Status != "ok" {
+			resp.Status = "unavailable"
+		}
+	}
+
+	status := http.StatusOK
+	if resp.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	return response.JSON(c, status, resp)
+}
+
+// run calls check with the timeout applied. A check that ignores its context
+// is abandoned when the timeout passes.
+func (h *HealthHandler) run(ctx context.Context, check HealthCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.New("check panicked")
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: "ok", DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = "failed"
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Status = "timeout"
+		}
+		if h.showErrors {
+			result.Error = err.Error()
+		}
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newTestHealthHandler(t *testing.T, timeout time.Duration, showErrors bool) *HealthHandler {
+	t.Helper()
+	h, err := NewHealthHandler(timeout, showErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+// ready calls h.Ready and decodes the readiness body
+func ready(t *testing.T, h *HealthHandler) (int, ReadinessResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/readyz", nil), rec)
+	if err := h.Ready(c); err != nil {
+		t.Fatal(err)
+	}
+
+	var body struct {
+		Data ReadinessResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, body.Data
+}
+
+func passing(ctx context.Context) error { return nil }
+
+func TestNewHealthHandlerRejectsInvalidTimeout(t *testing.T) {
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		if _, err := NewHealthHandler(timeout, false); err == nil {
+			t.Errorf("NewHealthHandler accepted a timeout of %s", timeout)
+		}
+	}
+}
+
+func TestReadyPassesWhenEveryCheckDoes(t *testing.T) {
+	h := newTestHealthHandler(t, time.Second, true)
+	h.Register("database", passing)
+	h.Register("cache", passing)
+
+	status, resp := ready(t, h)
+	if status != http.StatusOK || resp.Status != "ok" {
+		t.Errorf("status %d %q, want 200 ok", status, resp.Status)
+	}
+	for _, name := range []string{"database", "cache"} {
+		if resp.Checks[name].Status != "ok" {
+			t.Errorf("%s = %+v, want ok", name, resp.Checks[name])
+		}
+	}
+}
+
+func TestReadyFailsWhenACheckFails(t *testing.T) {
+	for _, showErrors := range []bool{true, false} {
+		h := newTestHealthHandler(t, time.Second, showErrors)
+		h.Register("database", passing)
+		h.Register("cache", func(ctx context.Context) error { return errors.New("connection refused") })
+		h.Register("broken", func(ctx context.Context) error { panic("boom") })
+
+		status, resp := ready(t, h)
+		if status != http.StatusServiceUnavailable || resp.Status != "unavailable" {
+			t.Errorf("status %d %q, want 503 unavailable", status, resp.Status)
+		}
+		if resp.Checks["database"].Status != "ok" {
+			t.Errorf("database = %+v, want ok", resp.Checks["database"])
+		}
+		for _, name := range []string{"cache", "broken"} {
+			if resp.Checks[name].Status != "failed" {
+				t.Errorf("%s = %+v, want failed", name, resp.Checks[name])
+			}
+		}
+
+		// Errors may reveal internals, so they are only shown when asked for
+		if shown := resp.Checks["cache"].Error != ""; shown != showErrors {
+			t.Errorf("showErrors %v: cache error %q", showErrors, resp.Checks["cache"].Error)
+		}
+	}
+}
+
+func TestReadyTimesOutEachCheck(t *testing.T) {
+	h := newTestHealthHandler(t, 20*time.Millisecond, true)
+	release := make(chan struct{})
+	defer close(release)
+	h.Register("respects context", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	h.Register("ignores context", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	h.Register("database", passing)
+
+	start := time.Now()
+	status, resp := ready(t, h)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Ready took %s, want it bounded by the check timeout", elapsed)
+	}
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", status)
+	}
+	for _, name := range []string{"respects context", "ignores context"} {
+		if resp.Checks[name].Status != "timeout" {
+			t.Errorf("%s = %+v, want timeout", name, resp.Checks[name])
+		}
+	}
+	if resp.Checks["database"].Status != "ok" {
+		t.Errorf("database = %+v, want ok", resp.Checks["database"])
+	}
+}
+
+func TestDrainFailsReadinessButNotLiveness(t *testing.T) {
+	h := newTestHealthHandler(t, time.Second, true)
+	h.Register("database", passing)
+	h.Drain()
+
+	status, resp := ready(t, h)
+	if status != http.StatusServiceUnavailable || resp.Status != "draining" {
+		t.Errorf("status %d %q, want 503 draining", status, resp.Status)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := h.Live(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/healthz", nil), rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("liveness status = %d while draining, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/pkg/response"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// filterParam matches filter[field] and filter[field][op]
+var filterParam = regexp.MustCompile(`^filter\[(\w+)\](?:\[(\w+)\])?$`)
+
+// parseQuerySpec reads limit, offset, cursor, sort and filter query parameters.
+//
+//	?limit=20&offset=40
+//	?cursor=<next_cursor>
+//	?sort=-created_at,name
+//	?filter[role]=admin&filter[created_at][gte]=2024-01-01T00:00:00Z
+func parseQuerySpec(c echo.Context, schema query.Schema) (query.Spec, error) {
+	var spec query.Spec
+	params := c.QueryParams()
+
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return spec, echo.NewHTTPError(http.StatusBadRequest, "limit must be an integer")
+		}
+		spec.Limit = limit
+	}
+	if raw := params.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil {
+			return spec, echo.NewHTTPError(http.StatusBadRequest, "offset must be an integer")
+		}
+		spec.Offset = offset
+	}
+	spec.Cursor = params.Get("cursor")
+
+	if raw := params.Get("sort"); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			sort := query.Sort{Field: strings.TrimSpace(field), Direction: query.Asc}
+			if strings.HasPrefix(sort.Field, "-") {
+				sort.Field = sort.Field[1:]
+				sort.Direction = query.Desc
+			}
+			spec.Sort = append(spec.Sort, sort)
+		}
+	}
+
+	for key, values := range params {
+		m := filterParam.FindStringSubmatch(key)
+		if m == nil {
+			continue
+		}
+		op := query.Operator(m[2])
+		if op == "" {
+			op = query.OpEq
+		}
+		for _, raw := range values {
+			filter, err := schema.ParseFilter(m[1], op, raw)
+			if err != nil {
+				return spec, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+			spec.Filters = append(spec.Filters, filter)
+		}
+	}
+
+	if err := schema.Normalize(&spec); err != nil {
+		return spec, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return spec, nil
+}
+
+// paginated writes a query.Page using the shared paginated envelope
+func paginated[T any](c echo.Context, page *query.Page[T]) error {
+	return response.Paginated(c, http.StatusOK, page.Items, response.Meta{
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/usecase/trash"
+	"go-clean-v3/pkg/publicid"
+	"go-clean-v3/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TrashHandler struct {
+	trashUsecase *trash.TrashUsecase
+}
+
+func NewTrashHandler(trashUsecase *trash.TrashUsecase) *TrashHandler {
+	return &TrashHandler{trashUsecase: trashUsecase}
+}
+
+// List returns a page of what is currently in the trash
+func (h *TrashHandler) List(c echo.Context) error {
+	spec, err := parseQuerySpec(c, user.DeletedListSchema)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.trashUsecase.List(c.Request().Context(), spec)
+	if err != nil {
+		if errors.Is(err, query.ErrInvalidQuery) {
+			return response.Error(c, http.StatusBadRequest, err.Error(), err)
+		}
+		return err
+	}
+
+	return paginated(c, page)
+}
+
+// RestoreUser brings a soft-deleted user back
+func (h *TrashHandler) RestoreUser(c echo.Context) error {
+	id := c.Param("id")
+	if !publicid.Valid(id) {
+		return response.Error(c, http.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	if err := h.trashUsecase.RestoreUser(c.Request().Context(), id); err != nil {
+		switch {
+		case errors.Is(err, user.ErrUserNotFound):
+			return response.Error(c, http.StatusNotFound, "User not found in trash", err)
+		case errors.Is(err, user.ErrEmailExists):
+			return response.Error(c, http.StatusConflict, "Email is already registered", err)
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
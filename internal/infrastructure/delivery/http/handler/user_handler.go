@@ -1,52 +1,137 @@
-package handler
-
-import (
-	"go-clean-v3/internal/infrastructure/delivery/http/middleware"
-	"go-clean-v3/internal/usecase/user"
-	"go-clean-v3/pkg/response"
-	"net/http"
-
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/gommon/log"
-)
-
-type UserHandler struct {
-	userUsecase *user.UserUsecase
-}
-
-func NewUserHandler(userUsecase *user.UserUsecase) *UserHandler {
-	return &UserHandler{userUsecase: userUsecase}
-}
-
-// REgister handles user registration
-func (h *UserHandler) Register(c echo.Context) error {
-	var req user.RegisterUserRequest
-
-	if err := c.Bind(&req); err != nil {
-		log.Errorf("[UserHandler-Register-1] Bind error: %v", err)
-		return err
-	}
-
-	// Call usecase
-	userResp, err := h.userUsecase.Register(c.Request().Context(), req)
-	if err != nil {
-		log.Errorf("[UserHandler-Register-2] Usecase error: %v", err)
-		return err
-	}
-
-	return response.JSON(c, http.StatusCreated, userResp)
-}
-
-func (h *UserHandler) GetProfile(c echo.Context) error {
-	userID, err := middleware.GetUserIDFromToken(c)
-	if err != nil {
-		return err
-	}
-
-	userResp, err := h.userUsecase.GetProfile(c.Request().Context(), userID)
-	if err != nil {
-		return response.Error(c, http.StatusNotFound, "User not found", err)
-	}
-
-	return response.JSON(c, http.StatusOK, userResp)
-}
\ No newline at end of file
+package handler
+
+import (
+	"context"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	domainUser "go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/infrastructure/delivery/http/middleware"
+	"go-clean-v3/internal/usecase/user"
+	"go-clean-v3/pkg/logger"
+	"go-clean-v3/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type UserHandler struct {
+	userUsecase *user.UserUsecase
+}
+
+func NewUserHandler(userUsecase *user.UserUsecase) *UserHandler {
+	return &UserHandler{userUsecase: userUsecase}
+}
+
+// ResolveUser is the middleware.UserResolver for authenticated routes
+func (h *UserHandler) ResolveUser(ctx context.Context, publicID string) (*domainUser.User, error) {
+	return h.userUsecase.Resolve(ctx, publicID)
+}
+
+// REgister handles user registration
+func (h *UserHandler) Register(c echo.Context) error {
+	var req user.RegisterUserRequest
+
+	if err := bind(c, &req); err != nil {
+		logger.DebugContext(c.Request().Context(), "[UserHandler-Register-1] Bind error", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	// Call usecase
+	userResp, err := h.userUsecase.Register(c.Request().Context(), req)
+	if err != nil {
+		if domainErrors.KindOf(err) != domainErrors.KindInternal {
+			return err
+		}
+		logger.ErrorContext(c.Request().Context(), "[UserHandler-Register-2] Usecase error", map[string]interface{}{"error": err.Error()})
+		return err
+	}
+
+	return response.JSON(c, http.StatusCreated, userResp)
+}
+
+func (h *UserHandler) GetProfile(c echo.Context) error {
+	userID, err := middleware.GetUserIDFromToken(c)
+	if err != nil {
+		return err
+	}
+
+	userResp, err := h.userUsecase.GetProfile(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	middleware.SetETag(c, userResp.Version)
+	return response.JSON(c, http.StatusOK, userResp)
+}
+
+// UpdateProfile changes the current user's profile, guarded by If-Match
+func (h *UserHandler) UpdateProfile(c echo.Context) error {
+	userID, err := middleware.GetUserIDFromToken(c)
+	if err != nil {
+		return err
+	}
+
+	version, err := middleware.GetIfMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	var req user.UpdateProfileRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	userResp, err := h.userUsecase.UpdateProfile(c.Request().Context(), userID, version, req)
+	if err != nil {
+		return err
+	}
+
+	middleware.SetETag(c, userResp.Version)
+	return response.JSON(c, http.StatusOK, userResp)
+}
+// ChangePassword replaces the current user's password
+func (h *UserHandler) ChangePassword(c echo.Context) error {
+	userID, err := middleware.GetUserIDFromToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req user.ChangePasswordRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.userUsecase.ChangePassword(c.Request().Context(), userID, req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteAccount moves the current user's account to the trash
+func (h *UserHandler) DeleteAccount(c echo.Context) error {
+	userID, err := middleware.GetUserIDFromToken(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userUsecase.DeleteAccount(c.Request().Context(), userID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListUsers returns a page of users (admin only)
+func (h *UserHandler) ListUsers(c echo.Context) error {
+	spec, err := parseQuerySpec(c, domainUser.ListSchema)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.userUsecase.ListUsers(c.Request().Context(), spec)
+	if err != nil {
+		return err
+	}
+
+	return paginated(c, page)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/query"
+	domainUser "go-clean-v3/internal/domain/user"
+	domainWorkspace "go-clean-v3/internal/domain/workspace"
+	"go-clean-v3/internal/infrastructure/delivery/http/middleware"
+	"go-clean-v3/internal/usecase/workspace"
+	"go-clean-v3/pkg/publicid"
+	"go-clean-v3/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WorkspaceHandler struct {
+	workspaceUsecase *workspace.WorkspaceUsecase
+}
+
+func NewWorkspaceHandler(workspaceUsecase *workspace.WorkspaceUsecase) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceUsecase: workspaceUsecase}
+}
+
+// Authorize is the middleware.TenantAuthorizer for workspace scoped routes
+func (h *WorkspaceHandler) Authorize(ctx context.Context, userID int64, workspaceID string) (int64, error) {
+	membership, err := h.workspaceUsecase.Authorize(ctx, userID, workspaceID)
+	if err != nil {
+		return 0, err
+	}
+	return membership.WorkspaceID, nil
+}
+
+// Create makes a new workspace owned by the current user
+func (h *WorkspaceHandler) Create(c echo.Context) error {
+	userID, err := middleware.GetUserIDFromToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req workspace.CreateWorkspaceRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.workspaceUsecase.Create(c.Request().Context(), userID, req)
+	if err != nil {
+		return err
+	}
+
+	return response.JSON(c, http.StatusCreated, resp)
+}
+
+// ListMine returns the workspaces of the current user
+func (h *WorkspaceHandler) ListMine(c echo.Context) error {
+	userID, err := middleware.GetUserIDFromToken(c)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.workspaceUsecase.ListMine(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return response.JSON(c, http.StatusOK, resp)
+}
+
+// Token issues a token that selects the workspace by default
+func (h *WorkspaceHandler) Token(c echo.Context) error {
+	userID, err := middleware.GetUserIDFromToken(c)
+	if err != nil {
+		return err
+	}
+
+	workspaceID := c.Param("id")
+	if !publicid.Valid(workspaceID) {
+		return response.Error(c, http.StatusBadRequest, "Invalid workspace ID", nil)
+	}
+
+	resp, err := h.workspaceUsecase.Token(c.Request().Context(), userID, workspaceID)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrForbidden) {
+			return response.Error(c, http.StatusForbidden, "Not a member of this workspace", err)
+		}
+		return err
+	}
+
+	return response.JSON(c, http.StatusOK, resp)
+}
+
+// ListMembers returns a page of members of the current workspace
+func (h *WorkspaceHandler) ListMembers(c echo.Context) error {
+	spec, err := parseQuerySpec(c, domainWorkspace.MemberListSchema)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.workspaceUsecase.ListMembers(c.Request().Context(), spec)
+	if err != nil {
+		if errors.Is(err, query.ErrInvalidQuery) {
+			return response.Error(c, http.StatusBadRequest, err.Error(), err)
+		}
+		return err
+	}
+
+	return paginated(c, page)
+}
+
+// AddMember adds a user to the current workspace (owners only)
+func (h *WorkspaceHandler) AddMember(c echo.Context) error {
+	userID, err := middleware.GetUserIDFromToken(c)
+	if err != nil {
+		return err
+	}
+
+	var req workspace.AddMemberRequest
+	if err := bind(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.workspaceUsecase.AddMember(c.Request().Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domainErrors.ErrForbidden):
+			return response.Error(c, http.StatusForbidden, "Only owners can add members", err)
+		case errors.Is(err, domainUser.ErrUserNotFound):
+			return response.Error(c, http.StatusNotFound, "User not found", err)
+		}
+		return err
+	}
+
+	return response.JSON(c, http.StatusCreated, resp)
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"go-clean-v3/pkg/logger"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AccessLogFields are the fields an access log line can carry
+var AccessLogFields = []string{
+	"method", "route", "path", "status", "latency",
+	"bytes_in", "bytes_out", "user_id", "request_id", "remote_ip", "user_agent",
+}
+
+// DefaultAccessLogFields are logged when AccessLogConfig.Fields is empty
+var DefaultAccessLogFields = []string{
+	"method", "route", "status", "latency", "bytes_out", "user_id", "request_id",
+}
+
+type AccessLogConfig struct {
+	// Fields selects what each line carries, out of AccessLogFields
+	Fields []string
+	// SkipPaths are request paths or route templates that are not logged, such as health checks
+	SkipPaths []string
+}
+
+// AccessLog writes one structured line per request through pkg/logger. Server
+// errors are logged at error level, everything else at info.
+func AccessLog(cfg AccessLogConfig) echo.MiddlewareFunc {
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = DefaultAccessLogFields
+	}
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skip[c.Request().URL.Path] || skip[c.Path()] {
+				return next(c)
+			}
+
+			start := time.Now()
+			// Log the status the error handler writes, see NewServer
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			latency := time.Since(start)
+
+			// Without the request_id field the context is left out, so the
+			// logger does not add the ID on its own
+			ctx := context.Background()
+			values := make(map[string]interface{}, len(fields))
+			for _, field := range fields {
+				switch field {
+				case "method":
+					values[field] = c.Request().Method
+				case "route":
+					values[field] = c.Path()
+				case "path":
+					values[field] = c.Request().URL.Path
+				case "status":
+					values[field] = c.Response().Status
+				case "latency":
+					values["latency_ms"] = float64(latency.Microseconds()) / 1000
+				case "bytes_in":
+					values[field] = c.Request().ContentLength
+				case "bytes_out":
+					values[field] = c.Response().Size
+				case "user_id":
+					if userID, err := GetUserIDFromToken(c); err == nil {
+						values[field] = userID
+					}
+				case "request_id":
+					ctx = c.Request().Context()
+				case "remote_ip":
+					values[field] = c.RealIP()
+				case "user_agent":
+					values[field] = c.Request().UserAgent()
+				}
+			}
+
+			if c.Response().Status >= 500 {
+				logger.ErrorContext(ctx, "HTTP request", values)
+			} else {
+				logger.InfoContext(ctx, "HTTP request", values)
+			}
+			return nil
+		}
+	}
+}
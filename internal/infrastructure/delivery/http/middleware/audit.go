@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"go-clean-v3/internal/domain/audit"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuditActor stores who is calling, and from where, in the request context for audit entries.
+// Registered globally it records the client address; registered again after
+// JWTAuthMiddleware it adds the authenticated user.
+func AuditActor() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			actor := audit.ActorFromContext(req.Context())
+			actor.IP = c.RealIP()
+			actor.UserAgent = req.UserAgent()
+			if userID, err := GetUserIDFromToken(c); err == nil {
+				actor.UserID = userID
+			}
+
+			c.SetRequest(req.WithContext(audit.WithActor(req.Context(), actor)))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"go-clean-v3/internal/domain/transaction"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReadYourWrites makes every read that follows a write within the same request
+// go to the primary database instead of a possibly lagging replica.
+func ReadYourWrites() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(transaction.WithReadYourWrites(req.Context())))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireIfMatch rejects PUT and PATCH requests without an If-Match header
+// with 428 Precondition Required, so clients cannot overwrite changes blindly.
+func RequireIfMatch() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			method := c.Request().Method
+			if (method == http.MethodPut || method == http.MethodPatch) && c.Request().Header.Get("If-Match") == "" {
+				return echo.NewHTTPError(http.StatusPreconditionRequired, "If-Match header is required")
+			}
+			return next(c)
+		}
+	}
+}
+
+// SetETag exposes an entity version as the response ETag
+func SetETag(c echo.Context, version int64) {
+	c.Response().Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// GetIfMatchVersion returns the entity version the client sent in If-Match.
+// A malformed or weak tag can never match and yields errors.ErrPreconditionFailed.
+func GetIfMatchVersion(c echo.Context) (int64, error) {
+	tag := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+		return 0, domainErrors.ErrPreconditionFailed
+	}
+
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return 0, domainErrors.ErrPreconditionFailed
+	}
+	return version, nil
+}
@@ -1,28 +1,67 @@
-package middleware
-
-import (
-	"go-clean-v3/internal/config"
-
-	"github.com/golang-jwt/jwt/v5"
-	echojwt "github.com/labstack/echo-jwt/v4"
-	"github.com/labstack/echo/v4"
-)
-
-func JWTAuthMiddleware(cfg *config.Config) echo.MiddlewareFunc {
-	ecfg := echojwt.Config{
-		SigningKey: []byte(cfg.JWTSecret),
-	}
-
-	return echojwt.WithConfig(ecfg)
-}
-
-func GetUserIDFromToken(c echo.Context) (int64, error) {
-	user := c.Get("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-
-	userID, ok := claims["user_id"].(float64)
-	if !ok {
-		return -1, echo.ErrUnauthorized
-	}
-	return int64(userID), nil
-}
\ No newline at end of file
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/domain/user"
+
+	"github.com/golang-jwt/jwt/v5"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// contextKeyUserID and contextKeyUserRole hold the internal ID and the stored
+// role of the authenticated user
+const (
+	contextKeyUserID   = "user_id"
+	contextKeyUserRole = "user_role"
+)
+
+// UserResolver returns the user known by publicID, or user.ErrUserNotFound
+// when there is no such user
+type UserResolver func(ctx context.Context, publicID string) (*user.User, error)
+
+// JWTAuthMiddleware checks the token and resolves the public ID in its user_id
+// claim to the stored user, whose internal ID GetUserIDFromToken returns and
+// whose role RequireRole checks. Tokens of users that no longer exist are
+// rejected.
+func JWTAuthMiddleware(cfg *config.Config, resolve UserResolver) echo.MiddlewareFunc {
+	ecfg := echojwt.Config{
+		SigningKey: []byte(cfg.JWTSecret),
+	}
+	checkToken := echojwt.WithConfig(ecfg)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return checkToken(func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+			publicID, ok := token.Claims.(jwt.MapClaims)["user_id"].(string)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+
+			u, err := resolve(c.Request().Context(), publicID)
+			if err != nil {
+				if errors.Is(err, user.ErrUserNotFound) {
+					return echo.ErrUnauthorized
+				}
+				return err
+			}
+
+			c.Set(contextKeyUserID, u.ID)
+			c.Set(contextKeyUserRole, u.Role)
+			return next(c)
+		})
+	}
+}
+
+func GetUserIDFromToken(c echo.Context) (int64, error) {
+	userID, ok := c.Get(contextKeyUserID).(int64)
+	if !ok {
+		return -1, echo.ErrUnauthorized
+	}
+	return userID, nil
+}
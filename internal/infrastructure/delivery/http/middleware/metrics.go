@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"go-clean-v3/pkg/metrics"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics counts requests and records their latency in pkg/metrics, labeled by
+// method, route template and final status. Requests that match no route share
+// the "unmatched" route and non-standard methods the "OTHER" method, so probing
+// random paths or methods cannot add series.
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			// Count the status the error handler writes, see NewServer
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			metrics.ObserveHTTPRequest(methodLabel(c.Request().Method), route, c.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
+
+// methodLabel returns method if it is a standard HTTP method and "OTHER" otherwise
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMethodLabel(t *testing.T) {
+	tests := map[string]string{
+		http.MethodGet:     http.MethodGet,
+		http.MethodPatch:   http.MethodPatch,
+		http.MethodOptions: http.MethodOptions,
+		"PROPFIND":         "OTHER",
+		"get":              "OTHER",
+		"X-RANDOM-1234":    "OTHER",
+	}
+	for method, want := range tests {
+		if got := methodLabel(method); got != want {
+			t.Errorf("methodLabel(%q) = %q, want %q", method, got, want)
+		}
+	}
+}
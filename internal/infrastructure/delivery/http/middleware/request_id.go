@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"go-clean-v3/pkg/requestid"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestID takes the X-Request-ID of the request, or generates one when it is
+// missing or malformed, stores it in the request context and returns it in the
+// response headers. It should be the first middleware, so every log line of the
+// request carries the ID.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(requestid.Header)
+			if !requestid.Valid(id) {
+				id = requestid.New()
+			}
+
+			c.Response().Header().Set(requestid.Header, id)
+			c.SetRequest(c.Request().WithContext(requestid.NewContext(c.Request().Context(), id)))
+			return next(c)
+		}
+	}
+}
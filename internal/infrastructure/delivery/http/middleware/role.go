@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+// RequireRole rejects requests of users that do not have the given role. The
+// role is the stored one, not the token claim, so a demoted user loses access
+// right away. It must run after JWTAuthMiddleware.
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if GetUserRole(c) != role {
+				return echo.ErrForbidden
+			}
+			return next(c)
+		}
+	}
+}
+
+// GetUserRole returns the stored role of the user JWTAuthMiddleware resolved
+func GetUserRole(c echo.Context) string {
+	role, _ := c.Get(contextKeyUserRole).(string)
+	return role
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/domain/user"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequireRoleChecksStoredRole(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "secret"}
+	stored := map[string]*user.User{
+		"admin":   {ID: 1, PublicID: "admin", Role: user.RoleAdmin},
+		"demoted": {ID: 2, PublicID: "demoted", Role: user.RoleUser},
+	}
+	resolve := func(ctx context.Context, publicID string) (*user.User, error) {
+		if u, ok := stored[publicID]; ok {
+			return u, nil
+		}
+		return nil, user.ErrUserNotFound
+	}
+
+	e := echo.New()
+	e.GET("/admin", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) },
+		JWTAuthMiddleware(cfg, resolve), RequireRole(user.RoleAdmin))
+
+	// Both tokens were issued while the user was an admin
+	for publicID, want := range map[string]int{"admin": http.StatusNoContent, "demoted": http.StatusForbidden} {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": publicID,
+			"role":    user.RoleAdmin,
+		}).SignedString([]byte(cfg.JWTSecret))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", publicID, rec.Code, want)
+		}
+	}
+}
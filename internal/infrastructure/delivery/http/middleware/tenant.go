@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/workspace"
+	"go-clean-v3/pkg/publicid"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const HeaderWorkspaceID = "X-Workspace-ID"
+
+// TenantAuthorizer returns the internal ID of the workspace known by the public
+// workspaceID, or errors.ErrForbidden when userID may not act in it
+type TenantAuthorizer func(ctx context.Context, userID int64, workspaceID string) (int64, error)
+
+// Tenant resolves the workspace of the request from the X-Workspace-ID header, or
+// the workspace_id claim of a workspace token, checks membership and scopes the
+// request context to it. It must run after JWTAuthMiddleware.
+func Tenant(authorize TenantAuthorizer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, err := GetUserIDFromToken(c)
+			if err != nil {
+				return err
+			}
+
+			publicID, err := workspaceIDFromRequest(c)
+			if err != nil {
+				return err
+			}
+
+			ctx := c.Request().Context()
+			workspaceID, err := authorize(ctx, userID, publicID)
+			if err != nil {
+				if errors.Is(err, domainErrors.ErrForbidden) {
+					return echo.ErrForbidden
+				}
+				return err
+			}
+
+			c.SetRequest(c.Request().WithContext(workspace.WithTenant(ctx, workspaceID)))
+			return next(c)
+		}
+	}
+}
+
+func workspaceIDFromRequest(c echo.Context) (string, error) {
+	if header := c.Request().Header.Get(HeaderWorkspaceID); header != "" {
+		if !publicid.Valid(header) {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "Invalid "+HeaderWorkspaceID+" header")
+		}
+		return header, nil
+	}
+
+	if token, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			if id, ok := claims["workspace_id"].(string); ok && id != "" {
+				return id, nil
+			}
+		}
+	}
+
+	return "", echo.NewHTTPError(http.StatusBadRequest, HeaderWorkspaceID+" header or a workspace token is required")
+}
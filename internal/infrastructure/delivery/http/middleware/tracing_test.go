@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider that keeps every span and the W3C
+// propagator for the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func newTracedServer() *echo.Echo {
+	e := echo.New()
+	e.Use(RequestID())
+	e.Use(Tracing())
+	e.GET("/users/:id", func(c echo.Context) error {
+		switch c.Param("id") {
+		case "missing":
+			return echo.NewHTTPError(http.StatusNotFound)
+		case "broken":
+			return errors.New("database is down")
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	return e
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	recorder := recordSpans(t)
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	newTracedServer().ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+
+	if span.Name() != "GET /users/:id" {
+		t.Errorf("span name = %q, want the route template", span.Name())
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("span kind = %v, want server", span.SpanKind())
+	}
+	if got := span.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("trace ID = %s, want the incoming %s", got, traceID)
+	}
+	if got := span.Parent().SpanID().String(); got != parentID || !span.Parent().IsRemote() {
+		t.Errorf("parent = %s, want the remote span %s", got, parentID)
+	}
+
+	attrs := attributes(span)
+	if got := attrs["http.route"].AsString(); got != "/users/:id" {
+		t.Errorf("http.route = %q", got)
+	}
+	if got := attrs["http.response.status_code"].AsInt64(); got != http.StatusOK {
+		t.Errorf("http.response.status_code = %d", got)
+	}
+	if attrs["app.request_id"].AsString() == "" {
+		t.Error("span has no request ID")
+	}
+}
+
+func TestTracingStartsTraceWithoutParent(t *testing.T) {
+	recorder := recordSpans(t)
+
+	newTracedServer().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	if spans[0].Parent().IsValid() || !spans[0].SpanContext().TraceID().IsValid() {
+		t.Error("a request without traceparent did not start a new trace")
+	}
+}
+
+func TestTracingMarksServerErrors(t *testing.T) {
+	recorder := recordSpans(t)
+	e := newTracedServer()
+
+	tests := map[string]struct {
+		status int
+		code   codes.Code
+	}{
+		"/users/missing": {http.StatusNotFound, codes.Unset},
+		"/users/broken":  {http.StatusInternalServerError, codes.Error},
+	}
+	for path, want := range tests {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want.status {
+			t.Fatalf("%s: status = %d, want %d", path, rec.Code, want.status)
+		}
+
+		spans := recorder.Ended()
+		span := spans[len(spans)-1]
+		if got := attributes(span)["http.response.status_code"].AsInt64(); got != int64(want.status) {
+			t.Errorf("%s: http.response.status_code = %d, want %d", path, got, want.status)
+		}
+		if span.Status().Code != want.code {
+			t.Errorf("%s: span status = %v, want %v", path, span.Status().Code, want.code)
+		}
+	}
+}
@@ -1,42 +1,177 @@
-package middleware
-
-import (
-	"net/http"
-
-	"github.com/go-playground/locales/en"
-	ut "github.com/go-playground/universal-translator"
-	"github.com/go-playground/validator/v10"
-	enTranslations "github.com/go-playground/validator/v10/translations/en"
-	"github.com/labstack/echo/v4"
-)
-
-type CustomValidator struct {
-	validator *validator.Validate
-	translator ut.Translator
-}
-
-func NewCustomValidator() *CustomValidator {
-	validate := validator.New()
-	
-	enLocale := en.New()
-	uni := ut.New(enLocale, enLocale)
-	trans, _ := uni.GetTranslator("en")
-	
-	enTranslations.RegisterDefaultTranslations(validate, trans)
-
-	return &CustomValidator{
-		validator: validate,
-		translator: trans,
-	}
-}
-
-func (cv *CustomValidator) Validate(i interface{}) error {
-	err := cv.validator.Struct(i)
-
-	if err == nil {
-		return nil
-	}
-	
-	errs := err.(validator.ValidationErrors)
-	return echo.NewHTTPError(http.StatusBadRequest, errs.Translate(cv.translator))
-}
\ No newline at end of file
+package middleware
+
+import (
+	"errors"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// customTranslations are the messages of the rules the built-in translations
+// lack, by locale and tag
+var customTranslations = map[string]map[string]string{
+	"en": {
+		"strong_password": "{0} must be at least 8 characters and contain an uppercase letter, a lowercase letter and a digit",
+		"timezone":        "{0} must be a valid time zone",
+	},
+	"id": {
+		"strong_password": "{0} harus minimal 8 karakter dan mengandung huruf besar, huruf kecil dan angka",
+	},
+}
+
+type CustomValidator struct {
+	validator *validator.Validate
+	uni       *ut.UniversalTranslator
+}
+
+// NewCustomValidator returns the echo.Validator for request DTOs, with messages
+// in English and Indonesian. Fields are named by their json tag.
+func NewCustomValidator() *CustomValidator {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	if err := validate.RegisterValidation("strong_password", isStrongPassword); err != nil {
+		panic(err)
+	}
+
+	enLocale := en.New()
+	uni := ut.New(enLocale, enLocale, id.New())
+
+	enTrans, _ := uni.GetTranslator("en")
+	idTrans, _ := uni.GetTranslator("id")
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		panic(err)
+	}
+	if err := idTranslations.RegisterDefaultTranslations(validate, idTrans); err != nil {
+		panic(err)
+	}
+	for locale, messages := range customTranslations {
+		trans, _ := uni.GetTranslator(locale)
+		for tag, message := range messages {
+			if err := validate.RegisterTranslation(tag, trans, registerMessage(tag, message), translateField); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	return &CustomValidator{
+		validator: validate,
+		uni:       uni,
+	}
+}
+
+func registerMessage(tag string, message string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, message, true)
+	}
+}
+
+func translateField(trans ut.Translator, fe validator.FieldError) string {
+	message, err := trans.T(fe.Tag(), fe.Field())
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+// isStrongPassword requires at least 8 characters with an uppercase letter, a
+// lowercase letter and a digit
+func isStrongPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	var upper, lower, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return len([]rune(password)) >= 8 && upper && lower && digit
+}
+
+// Validate implements echo.Validator. Invalid input is returned as a
+// *ValidationError, to be translated once the language is known.
+func (cv *CustomValidator) Validate(i interface{}) error {
+	err := cv.validator.Struct(i)
+
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		return &ValidationError{errs: errs, uni: cv.uni}
+	}
+	return err
+}
+
+// ValidationError holds the failed rules of a request DTO
+type ValidationError struct {
+	errs validator.ValidationErrors
+	uni  *ut.UniversalTranslator
+}
+
+func (e *ValidationError) Error() string {
+	return e.errs.Error()
+}
+
+// Translate returns domainErrors.ErrInvalidInput with a detail per failed rule,
+// in the best language of an Accept-Language header
+func (e *ValidationError) Translate(acceptLanguage string) *domainErrors.AppError {
+	trans, _ := e.uni.FindTranslator(preferredLanguages(acceptLanguage)...)
+
+	details := make([]domainErrors.FieldError, len(e.errs))
+	for i, fe := range e.errs {
+		details[i] = domainErrors.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: fe.Translate(trans),
+		}
+	}
+	return domainErrors.ErrInvalidInput.Wrap(e).WithDetails(details...)
+}
+
+// preferredLanguages returns the base languages of an Accept-Language header,
+// most preferred first
+func preferredLanguages(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if base != "" && base != "*" && q > 0 {
+			langs = append(langs, weighted{lang: base, q: q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	result := make([]string, len(langs))
+	for i, l := range langs {
+		result[i] = l.lang
+	}
+	return result
+}
@@ -1,23 +1,60 @@
-package router
-
-import (
-	"go-clean-v3/internal/infrastructure/delivery/http/handler"
-
-	"github.com/labstack/echo/v4"
-)
-
-func RegisterRoutes(e *echo.Echo, h *handler.Handlers) {
-	// Public routes (no JWT required)
-	authGroup := e.Group("/api/auth")
-	authGroup.POST("/register", h.UserHandler.Register)
-	authGroup.POST("/login", h.AuthHandler.Login)
-
-	// Protected routes (JWT required)
-	// todoGroup := e.Group("/api/todos")
-	// todoGroup.Use(JWTAuthMiddleware())
-
-	// User profile (protected)
-	// userGroup := e.Group("/api/user")
-	// userGroup.Use(JWTAuthMiddleware())
-	// userGroup.GET("/me", userHandler.GetProfile)
-}
\ No newline at end of file
+package router
+
+import (
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/infrastructure/delivery/http/handler"
+	"go-clean-v3/internal/infrastructure/delivery/http/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(e *echo.Echo, h *handler.Handlers, cfg *config.Config) {
+	// Probes for the orchestrator
+	e.GET("/healthz", h.HealthHandler.Live)
+	e.GET("/readyz", h.HealthHandler.Ready)
+
+	// Public routes (no JWT required)
+	authGroup := e.Group("/api/auth")
+	authGroup.POST("/register", h.UserHandler.Register)
+	authGroup.POST("/login", h.AuthHandler.Login)
+
+	// Protected routes (JWT required)
+	// todoGroup := e.Group("/api/todos")
+	// todoGroup.Use(middleware.JWTAuthMiddleware(cfg))
+
+	// User profile (protected)
+	userGroup := e.Group("/api/user")
+	userGroup.Use(middleware.JWTAuthMiddleware(cfg, h.UserHandler.ResolveUser), middleware.AuditActor(), middleware.RequireIfMatch())
+	userGroup.GET("/me", h.UserHandler.GetProfile)
+	userGroup.PUT("/me", h.UserHandler.UpdateProfile)
+	userGroup.PATCH("/me", h.UserHandler.UpdateProfile)
+	userGroup.POST("/me/password", h.UserHandler.ChangePassword)
+	userGroup.DELETE("/me", h.UserHandler.DeleteAccount)
+
+	// Workspaces of the current user
+	workspacesGroup := e.Group("/api/workspaces")
+	workspacesGroup.Use(middleware.JWTAuthMiddleware(cfg, h.UserHandler.ResolveUser), middleware.AuditActor())
+	workspacesGroup.GET("", h.WorkspaceHandler.ListMine)
+	workspacesGroup.POST("", h.WorkspaceHandler.Create)
+	workspacesGroup.POST("/:id/token", h.WorkspaceHandler.Token)
+
+	// Current workspace (tenant from X-Workspace-ID or the token)
+	workspaceGroup := e.Group("/api/workspace")
+	workspaceGroup.Use(middleware.JWTAuthMiddleware(cfg, h.UserHandler.ResolveUser), middleware.AuditActor(), middleware.Tenant(h.WorkspaceHandler.Authorize))
+	workspaceGroup.GET("/members", h.WorkspaceHandler.ListMembers)
+	workspaceGroup.POST("/members", h.WorkspaceHandler.AddMember)
+
+	// Administration (admin only)
+	adminGroup := e.Group("/api/admin")
+	adminGroup.Use(middleware.JWTAuthMiddleware(cfg, h.UserHandler.ResolveUser), middleware.AuditActor(), middleware.RequireRole(user.RoleAdmin))
+	adminGroup.GET("/users", h.UserHandler.ListUsers)
+	adminGroup.GET("/audit", h.AuditHandler.List)
+	adminGroup.GET("/db/stats", h.DBHandler.Stats)
+
+	// Trash (admin only)
+	trashGroup := e.Group("/api/trash")
+	trashGroup.Use(middleware.JWTAuthMiddleware(cfg, h.UserHandler.ResolveUser), middleware.AuditActor(), middleware.RequireRole(user.RoleAdmin))
+	trashGroup.GET("", h.TrashHandler.List)
+	trashGroup.POST("/users/:id/restore", h.TrashHandler.RestoreUser)
+}
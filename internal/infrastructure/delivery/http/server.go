@@ -1,64 +1,121 @@
-package http
-
-import (
-	"context"
-	"go-clean-v3/internal/config"
-	"go-clean-v3/internal/infrastructure/delivery/http/handler"
-	"go-clean-v3/internal/infrastructure/delivery/http/router"
-	"log"
-	"os"
-	"os/signal"
-	"syscall"
-	"time"
-
-	jwtMiddleware "go-clean-v3/internal/infrastructure/delivery/http/middleware"
-
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
-)
-
-type Server struct {
-	echo *echo.Echo
-}
-
-func NewServer(cfg *config.Config) *Server {
-	e := echo.New()
-
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
-	e.Use(jwtMiddleware.JWTAuthMiddleware(cfg))
-
-	return &Server{echo: e}
-}
-
-// RegisterRoutes mounts all routes and middleware
-func (s *Server) RegisterRoutes(handlers *handler.Handlers) {
-	router.RegisterRoutes(s.echo, handlers)
-}
-
-// Run starts the HTTP server and listens for shudown signals
-func (s *Server) Run(port string) {
-	go func() {
-		log.Printf("🚀 Server starting on port %s", port)
-		if err := s.echo.Start(":"+port); err != nil {
-			log.Fatalf("🔥 Server failed to start: %v", err)
-		}
-	}()
-
-	// Wait for interrupt signal to gracefully shutdown the server with a timeout
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("⚠️  Shutting down server...")
-
-	// Create context with timeout for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := s.echo.Shutdown(ctx); err != nil {
-		log.Fatalf("🔥 Server forced to shutdown: %v", err)
-	}
-
-	log.Println("✅ Server exited properly")
+package http
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/infrastructure/delivery/http/handler"
+	appMiddleware "go-clean-v3/internal/infrastructure/delivery/http/middleware"
+	"go-clean-v3/internal/infrastructure/delivery/http/router"
+	"go-clean-v3/pkg/logger"
+	"go-clean-v3/pkg/metrics"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type Server struct {
+	echo *echo.Echo
+	// admin serves /metrics when cfg.MetricsPort is set, nil otherwise
+	admin *echo.Echo
+	cfg   *config.Config
+	// health is told to fail readiness once shutdown starts
+	health *handler.HealthHandler
+}
+
+func NewServer(cfg *config.Config) *Server {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.HTTPErrorHandler = NewErrorHandler(cfg)
+	e.Validator = appMiddleware.NewCustomValidator()
+
+	// The order matters. RequestID and Tracing come first, so the access log and
+	// every later log line carry the request and trace IDs. Tracing, AccessLog and
+	// Metrics hand an error to the error handler as soon as next returns it, rather
+	// than passing it up, so each sees the status the client gets; the outer ones
+	// then see nil, as the response is already written. Recover runs below them,
+	// so they see a panic as a 500.
+	e.Use(appMiddleware.RequestID())
+	e.Use(appMiddleware.Tracing())
+	e.Use(appMiddleware.AccessLog(appMiddleware.AccessLogConfig{
+		Fields:    cfg.AccessLogFields,
+		SkipPaths: cfg.AccessLogSkipPaths,
+	}))
+	e.Use(appMiddleware.Metrics())
+	e.Use(middleware.Recover())
+	e.Use(middleware.CORS())
+	e.Use(appMiddleware.AuditActor())
+	e.Use(appMiddleware.ReadYourWrites())
+
+	s := &Server{echo: e, cfg: cfg}
+
+	// Metrics go on the admin port when there is one, so they need not be exposed publicly
+	if cfg.MetricsPort == "" {
+		e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	} else {
+		s.admin = echo.New()
+		s.admin.HideBanner = true
+		s.admin.HidePort = true
+		s.admin.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	}
+
+	return s
+}
+
+// RegisterRoutes mounts all routes and middleware
+func (s *Server) RegisterRoutes(handlers *handler.Handlers) {
+	router.RegisterRoutes(s.echo, handlers, s.cfg)
+	s.health = handlers.HealthHandler
+}
+
+// Run starts the HTTP server and listens for shudown signals
+func (s *Server) Run(port string) {
+	go func() {
+		logger.Info("🚀 Server starting", map[string]interface{}{"port": port})
+		if err := s.echo.Start(":" + port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("🔥 Server failed to start", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+
+	if s.admin != nil {
+		go func() {
+			logger.Info("📈 Admin server starting", map[string]interface{}{"port": s.cfg.MetricsPort})
+			if err := s.admin.Start(":" + s.cfg.MetricsPort); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Fatal("🔥 Admin server failed to start", map[string]interface{}{"error": err.Error()})
+			}
+		}()
+	}
+
+	// Wait for interrupt signal to gracefully shutdown the server with a timeout
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	logger.Info("⚠️  Shutting down server...", nil)
+
+	// Fail readiness first and give the orchestrator time to notice
+	if s.health != nil {
+		s.health.Drain()
+	}
+	time.Sleep(s.cfg.ShutdownDrainDelay)
+
+	// Create context with timeout for shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.echo.Shutdown(ctx); err != nil {
+		logger.Fatal("🔥 Server forced to shutdown", map[string]interface{}{"error": err.Error()})
+	}
+	if s.admin != nil {
+		if err := s.admin.Shutdown(ctx); err != nil {
+			logger.Error("[Server-Run-1] Admin server forced to shutdown", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	logger.Info("✅ Server exited properly", nil)
 }
\ No newline at end of file
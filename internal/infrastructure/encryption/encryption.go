@@ -0,0 +1,232 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks values written by Encrypt, so plaintext stored before encryption
+// was enabled can be told apart
+const prefix = "enc:v1:"
+
+var (
+	ErrUnknownKey        = errors.New("encryption: unknown key")
+	ErrInvalidCiphertext = errors.New("encryption: invalid ciphertext")
+)
+
+// Service does envelope encryption: every value is sealed with its own random
+// data key, which is in turn sealed with a master key. The ID of that master key
+// is stored with the ciphertext, so old keys keep decrypting after the active key
+// is rotated.
+//
+// Ciphertexts look like enc:v1:<key id>:<sealed data key>:<sealed value>.
+type Service struct {
+	keys      map[string]cipher.AEAD
+	activeKey string
+	indexKey  []byte
+}
+
+// NewService builds a Service from config values. keys is a comma separated list
+// of id:base64 pairs of 32 byte master keys, activeKey is the ID new values are
+// encrypted with and indexKey is the base64 key for blind indexes.
+func NewService(keys string, activeKey string, indexKey string) (*Service, error) {
+	s := &Service{keys: map[string]cipher.AEAD{}, activeKey: activeKey}
+
+	for _, pair := range strings.Split(keys, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("encryption: key %q must be id:base64", pair)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: key %q: %w", id, err)
+		}
+		if s.keys[id], err = newAEAD(key); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := s.keys[activeKey]; !ok {
+		return nil, fmt.Errorf("%w: active key %q is not configured", ErrUnknownKey, activeKey)
+	}
+
+	var err error
+	if s.indexKey, err = decodeKey(indexKey); err != nil {
+		return nil, fmt.Errorf("encryption: blind index key: %w", err)
+	}
+	return s, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with aead, prepending the random nonce
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, data := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// Encrypt seals plaintext under a fresh data key wrapped with the active key
+func (s *Service) Encrypt(plaintext []byte) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(s.keys[s.activeKey], dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataAEAD, plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	return prefix + s.activeKey + ":" + enc.EncodeToString(wrappedKey) + ":" + enc.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with any configured key
+func (s *Service) Decrypt(ciphertext string) ([]byte, error) {
+	keyID, wrappedKey, sealed, err := parse(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	master, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+
+	dataKey, err := open(master, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return open(dataAEAD, sealed)
+}
+
+func parse(ciphertext string) (keyID string, wrappedKey []byte, sealed []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(ciphertext, prefix), ":")
+	if !IsEncrypted(ciphertext) || len(parts) != 3 {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+
+	enc := base64.RawURLEncoding
+	if wrappedKey, err = enc.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+	if sealed, err = enc.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+	return parts[0], wrappedKey, sealed, nil
+}
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// NeedsRotation reports whether value is plaintext or sealed with a key other
+// than the active one
+func (s *Service) NeedsRotation(value string) bool {
+	keyID, _, _, err := parse(value)
+	return err != nil || keyID != s.activeKey
+}
+
+// tokenData is the additional data of sealed tokens, so they can never be
+// mistaken for the data keys sealed with the same master key
+var tokenData = []byte("token")
+
+// SealToken encrypts a value handed to clients, such as a page cursor, with the
+// active key. Clients can neither read nor forge it. Tokens look like
+// <key id>.<sealed value>, which is safe in URLs.
+func (s *Service) SealToken(plaintext []byte) (string, error) {
+	aead := s.keys[s.activeKey]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, tokenData)
+	return s.activeKey + "." + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// OpenToken returns the value sealed by SealToken with any configured key
+func (s *Service) OpenToken(token string) ([]byte, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return nil, ErrInvalidCiphertext
+	}
+	aead, ok := s.keys[token[:i]]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, token[:i])
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, data := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, data, tokenData)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// BlindIndex returns a keyed hash of value for equality lookups on encrypted
+// columns. Equal values give equal indexes, but the index reveals nothing else.
+func (s *Service) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, s.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
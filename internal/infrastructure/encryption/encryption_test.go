@@ -0,0 +1,223 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// testKey returns a distinct base64 32 byte key for every seed
+func testKey(seed byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{seed}, 32))
+}
+
+func newTestService(t *testing.T, keys string, activeKey string) *Service {
+	t.Helper()
+	s, err := NewService(keys, activeKey, testKey(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	s := newTestService(t, "a:"+testKey(1), "a")
+
+	first, err := s.Encrypt([]byte("alice@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Encrypt([]byte("alice@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsEncrypted(first) || !strings.HasPrefix(first, prefix+"a:") {
+		t.Errorf("ciphertext %q does not name its key", first)
+	}
+	if first == second {
+		t.Error("equal plaintexts gave equal ciphertexts")
+	}
+	for _, ciphertext := range []string{first, second} {
+		plaintext, err := s.Decrypt(ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(plaintext) != "alice@example.com" {
+			t.Errorf("Decrypt = %q, want alice@example.com", plaintext)
+		}
+	}
+}
+
+func TestDecryptRejectsInvalidCiphertexts(t *testing.T) {
+	s := newTestService(t, "a:"+testKey(1), "a")
+	ciphertext, err := s.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(strings.TrimPrefix(ciphertext, prefix), ":")
+
+	// Flipping a bit of the sealed value breaks its authentication tag
+	sealed, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	sealed[len(sealed)-1] ^= 1
+	tampered := prefix + parts[0] + ":" + parts[1] + ":" + base64.RawURLEncoding.EncodeToString(sealed)
+
+	tests := map[string]struct {
+		ciphertext string
+		want       error
+	}{
+		"plaintext":        {"secret", ErrInvalidCiphertext},
+		"missing part":     {prefix + parts[0] + ":" + parts[1], ErrInvalidCiphertext},
+		"bad encoding":     {prefix + parts[0] + ":" + parts[1] + ":!!", ErrInvalidCiphertext},
+		"tampered":         {tampered, ErrInvalidCiphertext},
+		"swapped data key": {prefix + parts[0] + ":" + parts[2] + ":" + parts[1], ErrInvalidCiphertext},
+		"unknown key":      {prefix + "b:" + parts[1] + ":" + parts[2], ErrUnknownKey},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Decrypt(tt.ciphertext); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	before := newTestService(t, "a:"+testKey(1), "a")
+	old, err := before.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// b becomes the active key, a is kept to read older values
+	after := newTestService(t, "a:"+testKey(1)+", b:"+testKey(2), "b")
+	plaintext, err := after.Decrypt(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "secret" {
+		t.Errorf("Decrypt = %q after rotation, want secret", plaintext)
+	}
+
+	current, err := after.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(current, prefix+"b:") {
+		t.Errorf("ciphertext %q is not sealed with the active key", current)
+	}
+	for value, want := range map[string]bool{old: true, current: false, "secret": true} {
+		if got := after.NeedsRotation(value); got != want {
+			t.Errorf("NeedsRotation(%q) = %v, want %v", value, got, want)
+		}
+	}
+
+	// Once a is retired its values can no longer be read
+	retired := newTestService(t, "b:"+testKey(2), "b")
+	if _, err := retired.Decrypt(old); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestSealToken(t *testing.T) {
+	before := newTestService(t, "a:"+testKey(1), "a")
+	token, err := before.SealToken([]byte(`{"v":[42]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(token, "42") || url.QueryEscape(token) != token {
+		t.Errorf("token %q is readable or not URL safe", token)
+	}
+
+	// Tokens outlive a rotation as long as their key is configured
+	after := newTestService(t, "a:"+testKey(1)+",b:"+testKey(2), "b")
+	plaintext, err := after.OpenToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != `{"v":[42]}` {
+		t.Errorf("OpenToken = %q", plaintext)
+	}
+
+	keyID, sealed, _ := strings.Cut(token, ".")
+	raw, _ := base64.RawURLEncoding.DecodeString(sealed)
+	raw[len(raw)-1] ^= 1
+	ciphertext, err := before.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrappedKey := strings.Split(ciphertext, ":")[3]
+
+	tests := map[string]struct {
+		token string
+		want  error
+	}{
+		"no key":      {sealed, ErrInvalidCiphertext},
+		"unknown key": {"c." + sealed, ErrUnknownKey},
+		"tampered":    {keyID + "." + base64.RawURLEncoding.EncodeToString(raw), ErrInvalidCiphertext},
+		"truncated":   {keyID + ".AAAA", ErrInvalidCiphertext},
+		// A data key sealed with the same master key is not a token
+		"wrapped key": {keyID + "." + wrappedKey, ErrInvalidCiphertext},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := after.OpenToken(tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	s := newTestService(t, "a:"+testKey(1), "a")
+	index := s.BlindIndex("alice@example.com")
+
+	if len(index) != 64 {
+		t.Errorf("index %q is not a hex SHA-256", index)
+	}
+	if strings.Contains(index, "alice") {
+		t.Errorf("index %q reveals the value", index)
+	}
+	if s.BlindIndex("alice@example.com") != index {
+		t.Error("equal values gave different indexes")
+	}
+	if s.BlindIndex("bob@example.com") == index {
+		t.Error("different values gave equal indexes")
+	}
+
+	// The index depends on the index key only, not on the encryption keys
+	rotated := newTestService(t, "b:"+testKey(2), "b")
+	if rotated.BlindIndex("alice@example.com") != index {
+		t.Error("rotating the encryption keys changed the index")
+	}
+	other, err := NewService("a:"+testKey(1), "a", testKey(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.BlindIndex("alice@example.com") == index {
+		t.Error("different index keys gave equal indexes")
+	}
+}
+
+func TestNewServiceRejectsInvalidKeys(t *testing.T) {
+	tests := map[string]struct {
+		keys, activeKey, indexKey string
+	}{
+		"missing id":         {testKey(1), "a", testKey(9)},
+		"bad encoding":       {"a:not base64", "a", testKey(9)},
+		"short key":          {"a:" + base64.StdEncoding.EncodeToString([]byte("short")), "a", testKey(9)},
+		"unknown active key": {"a:" + testKey(1), "b", testKey(9)},
+		"no keys":            {"", "a", testKey(9)},
+		"bad index key":      {"a:" + testKey(1), "a", "short"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewService(tt.keys, tt.activeKey, tt.indexKey); err == nil {
+				t.Error("NewService accepted the keys")
+			}
+		})
+	}
+}
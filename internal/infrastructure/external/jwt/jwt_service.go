@@ -1,56 +1,76 @@
-package jwt
-
-import (
-	"go-clean-v3/internal/domain/user"
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
-)
-
-type jwtService struct {
-	secretKey string
-}
-
-func NewJWTService(secretKey string) *jwtService {
-	return &jwtService{
-		secretKey: secretKey,
-	}
-}
-
-func (j *jwtService) GenterateToken(u *user.User) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": u.ID,
-		"email":   u.Email,
-		"exp": time.Now().Add(time.Hour * 72).Unix(), // Token expires after 72 hours
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
-}
-
-func (j *jwtService) ValidateToken(tokenString string) (*user.User, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrTokenMalformed
-		}
-		return []byte(j.secretKey), nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	if !token.Valid {
-		return nil, jwt.ErrSignatureInvalid 
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, jwt.ErrInvalidType
-	}
-
-	return &user.User{
-		ID:    int64(claims["user_id"].(float64)),
-		Email: claims["email"].(string),
-	}, nil
+package jwt
+
+import (
+	"go-clean-v3/internal/domain/user"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type jwtService struct {
+	secretKey string
+}
+
+func NewJWTService(secretKey string) *jwtService {
+	return &jwtService{
+		secretKey: secretKey,
+	}
+}
+
+func (j *jwtService) GenerateToken(u *user.User) (string, error) {
+	return j.sign(j.claims(u))
+}
+
+func (j *jwtService) GenerateWorkspaceToken(u *user.User, workspaceID string) (string, error) {
+	claims := j.claims(u)
+	claims["workspace_id"] = workspaceID
+	return j.sign(claims)
+}
+
+func (j *jwtService) claims(u *user.User) jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id": u.PublicID,
+		"email":   u.Email,
+		"role":    u.Role,
+		"exp": time.Now().Add(time.Hour * 72).Unix(), // Token expires after 72 hours
+	}
+}
+
+func (j *jwtService) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.secretKey))
+}
+
+func (j *jwtService) ValidateToken(tokenString string) (*user.User, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrTokenMalformed
+		}
+		return []byte(j.secretKey), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, jwt.ErrSignatureInvalid 
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, jwt.ErrInvalidType
+	}
+
+	role, _ := claims["role"].(string)
+	userID, ok := claims["user_id"].(string)
+	if !ok {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return &user.User{
+		PublicID: userID,
+		Email:    claims["email"].(string),
+		Role:     role,
+	}, nil
 }
\ No newline at end of file
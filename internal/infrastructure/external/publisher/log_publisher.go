@@ -0,0 +1,25 @@
+package publisher
+
+import (
+	"context"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/pkg/logger"
+)
+
+type logPublisher struct{}
+
+// NewLogPublisher returns a publisher that only logs messages, for development
+func NewLogPublisher() event.Publisher {
+	return logPublisher{}
+}
+
+func (logPublisher) Publish(ctx context.Context, msg *event.Message) error {
+	logger.InfoContext(ctx, "Event published", map[string]interface{}{
+		"event_type":      msg.EventType,
+		"aggregate_type":  msg.AggregateType,
+		"aggregate_id":    msg.AggregateID,
+		"idempotency_key": msg.IdempotencyKey,
+		"payload":         string(msg.Payload),
+	})
+	return nil
+}
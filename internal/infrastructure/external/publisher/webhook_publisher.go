@@ -0,0 +1,78 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/pkg/requestid"
+	"go-clean-v3/pkg/tracing"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type webhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPublisher returns a publisher that POSTs every message as JSON to url.
+// The idempotency key is sent in the Idempotency-Key header, so the receiver can
+// drop messages delivered more than once, and the request that recorded the
+// event in X-Request-ID. The trace is continued through the W3C traceparent
+// header. Any non-2xx response is a failure.
+func NewWebhookPublisher(url string, timeout time.Duration) event.Publisher {
+	return &webhookPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *webhookPublisher) Publish(ctx context.Context, msg *event.Message) (err error) {
+	ctx, span := tracing.Start(ctx, "webhook publish",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(http.MethodPost),
+			attribute.String("app.event_type", msg.EventType),
+		),
+	)
+	defer tracing.End(span, &err)
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", msg.IdempotencyKey)
+	req.Header.Set("X-Event-Type", msg.EventType)
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+// Package drift compares the schema produced by the SQL migrations with the
+// schema the GORM models expect, so the two cannot silently diverge.
+package drift
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Difference is a single mismatch between a model and its table
+type Difference struct {
+	Table  string
+	Kind   string // missing_table, missing_column, extra_column, type, nullable, missing_index, extra_index, index
+	Name   string
+	Detail string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s %s: %s", d.Table, d.Kind, d.Name, d.Detail)
+}
+
+// Compare introspects the tables in db and reports how they differ from models.
+// Column types are compared by their normalized base type, so display widths and
+// datetime/timestamp or precision differences are not reported.
+func Compare(db *gorm.DB, models ...interface{}) ([]Difference, error) {
+	var diffs []Difference
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+
+		d, err := compareTable(db, stmt.Schema, model)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stmt.Schema.Table, err)
+		}
+		diffs = append(diffs, d...)
+	}
+
+	return diffs, nil
+}
+
+func compareTable(db *gorm.DB, s *schema.Schema, model interface{}) ([]Difference, error) {
+	table := s.Table
+	if !db.Migrator().HasTable(model) {
+		return []Difference{{Table: table, Kind: "missing_table", Name: table, Detail: "table does not exist"}}, nil
+	}
+
+	var diffs []Difference
+
+	columns, err := db.Migrator().ColumnTypes(model)
+	if err != nil {
+		return nil, err
+	}
+	actual := make(map[string]gorm.ColumnType, len(columns))
+	for _, column := range columns {
+		actual[column.Name()] = column
+	}
+
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.IgnoreMigration {
+			continue
+		}
+
+		column, ok := actual[field.DBName]
+		if !ok {
+			diffs = append(diffs, Difference{Table: table, Kind: "missing_column", Name: field.DBName, Detail: "column does not exist"})
+			continue
+		}
+		delete(actual, field.DBName)
+
+		want := normalizeType(db.Dialector.DataTypeOf(field))
+		got, _ := column.ColumnType()
+		if got == "" {
+			got = column.DatabaseTypeName()
+		}
+		if got = normalizeType(got); got != want {
+			diffs = append(diffs, Difference{Table: table, Kind: "type", Name: field.DBName, Detail: fmt.Sprintf("model %s, database %s", want, got)})
+		}
+
+		// Primary keys are always NOT NULL, whatever the driver reports
+		wantNull := !field.NotNull
+		if gotNull, ok := column.Nullable(); ok && !field.PrimaryKey && gotNull != wantNull {
+			diffs = append(diffs, Difference{Table: table, Kind: "nullable", Name: field.DBName, Detail: fmt.Sprintf("model %s, database %s", nullability(wantNull), nullability(gotNull))})
+		}
+	}
+
+	var extra []string
+	for name := range actual {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		diffs = append(diffs, Difference{Table: table, Kind: "extra_column", Name: name, Detail: "column is not mapped by the model"})
+	}
+
+	indexDiffs, err := compareIndexes(db, s, model)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(diffs, indexDiffs...), nil
+}
+
+func compareIndexes(db *gorm.DB, s *schema.Schema, model interface{}) ([]Difference, error) {
+	table := s.Table
+
+	indexes, err := db.Migrator().GetIndexes(model)
+	if err != nil {
+		return nil, err
+	}
+	actual := make(map[string]gorm.Index, len(indexes))
+	for _, index := range indexes {
+		if primary, _ := index.PrimaryKey(); primary {
+			continue
+		}
+		actual[index.Name()] = index
+	}
+
+	var diffs []Difference
+	for _, want := range s.ParseIndexes() {
+		var wantColumns []string
+		for _, option := range want.Fields {
+			wantColumns = append(wantColumns, option.DBName)
+		}
+		wantUnique := want.Class == "UNIQUE"
+
+		got, ok := actual[want.Name]
+		if !ok {
+			diffs = append(diffs, Difference{Table: table, Kind: "missing_index", Name: want.Name, Detail: describeIndex(wantColumns, wantUnique)})
+			continue
+		}
+		delete(actual, want.Name)
+
+		gotUnique, _ := got.Unique()
+		if gotUnique != wantUnique || strings.Join(got.Columns(), ",") != strings.Join(wantColumns, ",") {
+			diffs = append(diffs, Difference{Table: table, Kind: "index", Name: want.Name, Detail: fmt.Sprintf("model %s, database %s", describeIndex(wantColumns, wantUnique), describeIndex(got.Columns(), gotUnique))})
+		}
+	}
+
+	var extra []string
+	for name := range actual {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		unique, _ := actual[name].Unique()
+		diffs = append(diffs, Difference{Table: table, Kind: "extra_index", Name: name, Detail: describeIndex(actual[name].Columns(), unique)})
+	}
+
+	return diffs, nil
+}
+
+var typeWidth = regexp.MustCompile(`\(.*\)`)
+
+// normalizeType reduces a column type to what both sides can agree on:
+// lower case, without modifiers such as AUTO_INCREMENT or unsigned, without
+// display widths on integers and without precision on time types
+func normalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if i := strings.IndexByte(t, ' '); i >= 0 {
+		t = t[:i]
+	}
+
+	base := typeWidth.ReplaceAllString(t, "")
+	switch base {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if base == "integer" {
+			return "int"
+		}
+		if base == "tinyint" && t == "tinyint(1)" {
+			return "boolean"
+		}
+		return base
+	case "bool", "boolean":
+		return "boolean"
+	case "datetime", "timestamp":
+		return "datetime"
+	}
+	return t
+}
+
+func nullability(nullable bool) string {
+	if nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+func describeIndex(columns []string, unique bool) string {
+	kind := "index"
+	if unique {
+		kind = "unique index"
+	}
+	return fmt.Sprintf("%s (%s)", kind, strings.Join(columns, ", "))
+}
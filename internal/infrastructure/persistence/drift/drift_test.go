@@ -0,0 +1,27 @@
+package drift
+
+import "testing"
+
+func TestNormalizeType(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"bigint AUTO_INCREMENT", "bigint"},
+		{"BIGINT(20) unsigned", "bigint"},
+		{"int(11)", "int"},
+		{"integer", "int"},
+		{"tinyint(1)", "boolean"},
+		{"tinyint(4)", "tinyint"},
+		{"bool", "boolean"},
+		{"datetime(3)", "datetime"},
+		{"timestamp", "datetime"},
+		{"varchar(100)", "varchar(100)"},
+		{"char(64) AS (CASE WHEN deleted_at IS NULL THEN email_index END) VIRTUAL", "char(64)"},
+		{" JSON ", "json"},
+	}
+	for _, tt := range tests {
+		if got := normalizeType(tt.in); got != tt.want {
+			t.Errorf("normalizeType(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
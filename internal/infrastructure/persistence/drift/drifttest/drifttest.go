@@ -0,0 +1,32 @@
+// Package drifttest lets integration tests fail when the migrations and the GORM
+// models disagree.
+package drifttest
+
+import (
+	"os"
+	"testing"
+
+	"go-clean-v3/internal/infrastructure/persistence/drift"
+)
+
+// RequireNoDrift fails t if migrating a scratch database on the server at dsn
+// does not produce the schema the models expect. An empty dsn falls back to
+// TEST_DB_URL, and the test is skipped when that is unset too.
+func RequireNoDrift(t testing.TB, dsn string) {
+	t.Helper()
+
+	if dsn == "" {
+		dsn = os.Getenv("TEST_DB_URL")
+	}
+	if dsn == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	diffs, err := drift.Check(dsn)
+	if err != nil {
+		t.Fatalf("schema drift check failed: %v", err)
+	}
+	for _, diff := range diffs {
+		t.Errorf("schema drift: %s", diff)
+	}
+}
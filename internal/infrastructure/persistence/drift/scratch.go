@@ -0,0 +1,53 @@
+package drift
+
+import (
+	"fmt"
+	"strings"
+
+	"go-clean-v3/internal/infrastructure/persistence/gorm/models"
+	"go-clean-v3/internal/infrastructure/persistence/migrate"
+	"go-clean-v3/migrations"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Check applies every migration to a scratch database on the server at dsn,
+// compares the result with the GORM models and drops the scratch database again.
+// The database named in dsn itself is never touched.
+func Check(dsn string) ([]Difference, error) {
+	scratch, drop, err := migrate.Scratch(dsn, "drift")
+	if err != nil {
+		return nil, err
+	}
+	defer drop()
+
+	return CheckDatabase(scratch)
+}
+
+// CheckDatabase migrates the database at dsn to the latest version and compares it
+// with the GORM models. Use it on a database that may be changed freely.
+func CheckDatabase(dsn string) ([]Difference, error) {
+	mg, err := migrate.New(dsn, migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	err = mg.Up()
+	mg.Close()
+	if err != nil {
+		return nil, fmt.Errorf("migration failed: %w", err)
+	}
+
+	db, err := gorm.Open(mysql.Open(strings.TrimPrefix(dsn, "mysql://")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	return Compare(db, models.All()...)
+}
@@ -0,0 +1,95 @@
+package gorm
+
+import (
+	"context"
+	"go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/infrastructure/persistence/gorm/models"
+
+	"gorm.io/gorm"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+var auditColumns = columns{
+	"id":          "id",
+	"actor_id":    "actor_id",
+	"action":      "action",
+	"entity_type": "entity_type",
+	"entity_id":   "entity_id",
+	"ip":          "ip",
+	"created_at":  "created_at",
+}
+
+// toAuditModel converts domain audit Entry to GORM model
+func toAuditModel(e *audit.Entry) *models.AuditLogModel {
+	return &models.AuditLogModel{
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		Before:     e.Before,
+		After:      e.After,
+	}
+}
+
+// toAuditDomain converts GORM model to domain audit Entry
+func toAuditDomain(m *models.AuditLogModel) *audit.Entry {
+	return &audit.Entry{
+		ID:         m.ID,
+		ActorID:    m.ActorID,
+		Action:     m.Action,
+		EntityType: m.EntityType,
+		EntityID:   m.EntityID,
+		IP:         m.IP,
+		UserAgent:  m.UserAgent,
+		Before:     m.Before,
+		After:      m.After,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// Log implements audit.AuditLogger.
+func (a *auditRepository) Log(ctx context.Context, entry *audit.Entry) error {
+	actor := audit.ActorFromContext(ctx)
+	if entry.ActorID == nil && actor.UserID != 0 {
+		actorID := actor.UserID
+		entry.ActorID = &actorID
+	}
+	if entry.IP == "" {
+		entry.IP = actor.IP
+	}
+	if entry.UserAgent == "" {
+		entry.UserAgent = actor.UserAgent
+	}
+	if len(entry.UserAgent) > 255 {
+		entry.UserAgent = entry.UserAgent[:255]
+	}
+
+	model := toAuditModel(entry)
+	if err := conn(ctx, a.db).Create(model).Error; err != nil {
+		return err
+	}
+
+	entry.ID = model.ID
+	entry.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// List implements audit.AuditRepositoryInterface.
+func (a *auditRepository) List(ctx context.Context, spec query.Spec) (*query.Page[*audit.Entry], error) {
+	page, err := findPage[models.AuditLogModel](conn(ctx, a.db), spec, audit.ListSchema, auditColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.MapPage(page, func(m models.AuditLogModel) *audit.Entry { return toAuditDomain(&m) }), nil
+}
+
+func NewAuditRepository(db *gorm.DB) audit.AuditRepositoryInterface {
+	return &auditRepository{db: db}
+}
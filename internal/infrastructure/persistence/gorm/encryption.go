@@ -0,0 +1,144 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"go-clean-v3/internal/infrastructure/encryption"
+	"go-clean-v3/internal/infrastructure/persistence/gorm/models"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// fieldEncryption is a GORM plugin that provides the "encrypted" serializer, so
+// model fields tagged serializer:encrypted are stored encrypted and read back in
+// plaintext. Serializers are global in GORM, so a process has one keyring.
+type fieldEncryption struct {
+	service *encryption.Service
+}
+
+func (fieldEncryption) Name() string {
+	return "app:field_encryption"
+}
+
+func (f fieldEncryption) Initialize(db *gorm.DB) error {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{service: f.service})
+	return nil
+}
+
+// EncryptionService returns the Service NewDB registered on db
+func EncryptionService(db *gorm.DB) *encryption.Service {
+	if plugin, ok := db.Config.Plugins[fieldEncryption{}.Name()].(fieldEncryption); ok {
+		return plugin.service
+	}
+	panic("gorm: field encryption is not registered, use NewDB")
+}
+
+// encryptedSerializer encrypts string fields on write. Values stored before the
+// field was encrypted are read as they are, until Reencrypt has rewritten them.
+type encryptedSerializer struct {
+	service *encryption.Service
+}
+
+func (s encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("encrypted field %s: unsupported database type %T", field.Name, dbValue)
+	}
+
+	plaintext := stored
+	if encryption.IsEncrypted(stored) {
+		decrypted, err := s.service.Decrypt(stored)
+		if err != nil {
+			return fmt.Errorf("encrypted field %s: %w", field.Name, err)
+		}
+		plaintext = string(decrypted)
+	}
+
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+func (s encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted field %s: only strings can be encrypted, got %T", field.Name, fieldValue)
+	}
+	return s.service.Encrypt([]byte(plaintext))
+}
+
+// ReencryptUsers rewrites the encrypted user columns that are still plaintext or
+// sealed with an old key, and refreshes their blind index. With all set every row
+// is rewritten, which is needed after the blind index key has changed. A row is
+// only rewritten if its email still holds the value that was read, so a concurrent
+// change is not overwritten; such rows are skipped and can be picked up by another
+// run. It returns how many rows were rewritten and how many were skipped.
+func ReencryptUsers(ctx context.Context, db *gorm.DB, all bool, batchSize int) (rewritten int, skipped int, err error) {
+	service := EncryptionService(db)
+
+	// Read the stored values without the serializer to see which key they use
+	type storedUser struct {
+		ID         int64
+		Email      string
+		EmailIndex *string
+	}
+
+	var lastID int64
+	for {
+		var rows []storedUser
+		err := db.WithContext(ctx).Table(models.UserModel{}.TableName()).
+			Select("id, email, email_index").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return rewritten, skipped, err
+		}
+		if len(rows) == 0 {
+			return rewritten, skipped, nil
+		}
+		lastID = rows[len(rows)-1].ID
+
+		for _, row := range rows {
+			if !all && row.EmailIndex != nil && !service.NeedsRotation(row.Email) {
+				continue
+			}
+
+			email := row.Email
+			if encryption.IsEncrypted(email) {
+				decrypted, err := service.Decrypt(email)
+				if err != nil {
+					return rewritten, skipped, fmt.Errorf("user %d: %w", row.ID, err)
+				}
+				email = string(decrypted)
+			}
+
+			ciphertext, err := service.Encrypt([]byte(email))
+			if err != nil {
+				return rewritten, skipped, err
+			}
+			result := db.WithContext(ctx).Table(models.UserModel{}.TableName()).
+				Where("id = ? AND email = ?", row.ID, row.Email).
+				Updates(map[string]interface{}{
+					"email":       ciphertext,
+					"email_index": service.BlindIndex(email),
+				})
+			if result.Error != nil {
+				return rewritten, skipped, fmt.Errorf("user %d: %w", row.ID, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				skipped++
+				continue
+			}
+			rewritten++
+		}
+	}
+}
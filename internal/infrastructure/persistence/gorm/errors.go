@@ -0,0 +1,170 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// errorTranslator is a GORM plugin that turns constraint violations and deadlocks
+// of MySQL, Postgres and SQLite into domainErrors.ConstraintError, so repositories
+// do not depend on driver error codes.
+type errorTranslator struct{}
+
+func (errorTranslator) Name() string {
+	return "app:translate_errors"
+}
+
+func (errorTranslator) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("app:translate_errors", replaceError); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("app:translate_errors", replaceError); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("app:translate_errors", replaceError); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("app:translate_errors", replaceError); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("app:translate_errors", replaceError); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("app:translate_errors", replaceError)
+}
+
+func replaceError(db *gorm.DB) {
+	if db.Error != nil {
+		db.Error = translateError(db.Error)
+	}
+}
+
+var (
+	mysqlKeyPattern        = regexp.MustCompile(`for key '([^']+)'`)
+	mysqlConstraintPattern = regexp.MustCompile("CONSTRAINT `([^`]+)`")
+	mysqlColumnPattern     = regexp.MustCompile(`(?:Column|Field) '([^']+)'`)
+)
+
+// translateError maps a driver error to a ConstraintError, other errors are
+// returned as they are
+func translateError(err error) error {
+	var constraintErr *domainErrors.ConstraintError
+	if err == nil || errors.As(err, &constraintErr) {
+		return err
+	}
+
+	if kind, constraint := classify(err); kind != nil {
+		return &domainErrors.ConstraintError{Kind: kind, Constraint: constraint, Err: err}
+	}
+	return err
+}
+
+func classify(err error) (kind error, constraint string) {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return classifyMySQL(mysqlErr)
+	}
+
+	// Postgres drivers (pgx, lib/pq) report an SQLSTATE
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		return classifyPostgres(pgErr.SQLState()), stringField(pgErr, "ConstraintName", "Constraint", "ColumnName", "Column")
+	}
+
+	return classifySQLite(err)
+}
+
+func classifyMySQL(err *mysql.MySQLError) (error, string) {
+	switch err.Number {
+	case 1062, 1586: // ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME
+		key := submatch(mysqlKeyPattern, err.Message)
+		// MySQL 8 prefixes the key with its table
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key = key[i+1:]
+		}
+		return domainErrors.ErrDuplicate, key
+	case 1451, 1452: // ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2
+		return domainErrors.ErrReference, submatch(mysqlConstraintPattern, err.Message)
+	case 1048, 1364: // ER_BAD_NULL_ERROR, ER_NO_DEFAULT_FOR_FIELD
+		return domainErrors.ErrMissingValue, submatch(mysqlColumnPattern, err.Message)
+	case 1213: // ER_LOCK_DEADLOCK
+		return domainErrors.ErrDeadlock, ""
+	}
+	return nil, ""
+}
+
+func classifyPostgres(state string) error {
+	switch state {
+	case "23505": // unique_violation
+		return domainErrors.ErrDuplicate
+	case "23503": // foreign_key_violation
+		return domainErrors.ErrReference
+	case "23502": // not_null_violation
+		return domainErrors.ErrMissingValue
+	case "40P01": // deadlock_detected
+		return domainErrors.ErrDeadlock
+	}
+	return nil
+}
+
+var sqliteConstraints = []struct {
+	prefix string
+	kind   error
+}{
+	{"UNIQUE constraint failed", domainErrors.ErrDuplicate},
+	{"FOREIGN KEY constraint failed", domainErrors.ErrReference},
+	{"NOT NULL constraint failed", domainErrors.ErrMissingValue},
+}
+
+// classifySQLite goes by message, which is the same for every SQLite driver,
+// e.g. "UNIQUE constraint failed: users.email_index". SQLite has no deadlocks,
+// writers wait for the database lock instead.
+func classifySQLite(err error) (error, string) {
+	msg := err.Error()
+	for _, c := range sqliteConstraints {
+		if i := strings.Index(msg, c.prefix); i >= 0 {
+			// Drivers may append the result code, as in "... users.email_index (2067)"
+			constraint, _, _ := strings.Cut(strings.TrimPrefix(msg[i+len(c.prefix):], ": "), " (")
+			return c.kind, constraint
+		}
+	}
+	return nil, ""
+}
+
+func submatch(pattern *regexp.Regexp, s string) string {
+	if m := pattern.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// stringField returns the first non-empty one of the named string fields of a
+// driver error struct
+func stringField(v interface{}, names ...string) string {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range names {
+		if f := rv.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+// duplicateAs returns target wrapped around err when err is a duplicate key error
+func duplicateAs(err error, target error) error {
+	if errors.Is(err, domainErrors.ErrDuplicate) {
+		return fmt.Errorf("%w: %w", target, err)
+	}
+	return err
+}
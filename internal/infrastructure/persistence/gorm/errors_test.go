@@ -0,0 +1,163 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/user"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// pgError has the shape of pgconn.PgError, which the Postgres driver returns
+type pgError struct {
+	Code           string
+	Message        string
+	ConstraintName string
+	ColumnName     string
+}
+
+func (e *pgError) Error() string    { return "ERROR: " + e.Message + " (SQLSTATE " + e.Code + ")" }
+func (e *pgError) SQLState() string { return e.Code }
+
+func TestTranslateError(t *testing.T) {
+	tests := map[string]struct {
+		err        error
+		kind       error
+		constraint string
+	}{
+		// MySQL
+		"mysql 8 duplicate": {
+			&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '3f1a9c' for key 'users.idx_users_live_email_index'"},
+			domainErrors.ErrDuplicate, "idx_users_live_email_index",
+		},
+		"mysql 5.7 duplicate": {
+			&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'u_8Kq2' for key 'idx_users_public_id'"},
+			domainErrors.ErrDuplicate, "idx_users_public_id",
+		},
+		"mysql duplicate with key name": {
+			&mysql.MySQLError{Number: 1586, Message: "Duplicate entry '1-2' for key 'workspace_members.idx_workspace_members_workspace_user'"},
+			domainErrors.ErrDuplicate, "idx_workspace_members_workspace_user",
+		},
+		"mysql missing reference": {
+			&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`app`.`workspace_members`, CONSTRAINT `fk_workspace_members_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE)"},
+			domainErrors.ErrReference, "fk_workspace_members_user",
+		},
+		"mysql row still referenced": {
+			&mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row: a foreign key constraint fails (`app`.`workspace_members`, CONSTRAINT `fk_workspace_members_workspace` FOREIGN KEY (`workspace_id`) REFERENCES `workspaces` (`id`))"},
+			domainErrors.ErrReference, "fk_workspace_members_workspace",
+		},
+		"mysql null column": {
+			&mysql.MySQLError{Number: 1048, Message: "Column 'name' cannot be null"},
+			domainErrors.ErrMissingValue, "name",
+		},
+		"mysql no default": {
+			&mysql.MySQLError{Number: 1364, Message: "Field 'public_id' doesn't have a default value"},
+			domainErrors.ErrMissingValue, "public_id",
+		},
+		"mysql deadlock": {
+			&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock; try restarting transaction"},
+			domainErrors.ErrDeadlock, "",
+		},
+		"wrapped mysql duplicate": {
+			fmt.Errorf("create user: %w", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'users.idx_users_public_id'"}),
+			domainErrors.ErrDuplicate, "idx_users_public_id",
+		},
+
+		// Postgres
+		"postgres duplicate": {
+			&pgError{Code: "23505", Message: `duplicate key value violates unique constraint "idx_users_live_email_index"`, ConstraintName: "idx_users_live_email_index"},
+			domainErrors.ErrDuplicate, "idx_users_live_email_index",
+		},
+		"postgres missing reference": {
+			&pgError{Code: "23503", Message: `insert or update on table "workspace_members" violates foreign key constraint "fk_workspace_members_user"`, ConstraintName: "fk_workspace_members_user"},
+			domainErrors.ErrReference, "fk_workspace_members_user",
+		},
+		"postgres null column": {
+			&pgError{Code: "23502", Message: `null value in column "name" of relation "users" violates not-null constraint`, ColumnName: "name"},
+			domainErrors.ErrMissingValue, "name",
+		},
+		"postgres deadlock": {
+			&pgError{Code: "40P01", Message: "deadlock detected"},
+			domainErrors.ErrDeadlock, "",
+		},
+
+		// SQLite
+		"sqlite duplicate": {
+			errors.New("UNIQUE constraint failed: users.email_index"),
+			domainErrors.ErrDuplicate, "users.email_index",
+		},
+		"sqlite duplicate with result code": {
+			errors.New("constraint failed: UNIQUE constraint failed: users.live_email_index (2067)"),
+			domainErrors.ErrDuplicate, "users.live_email_index",
+		},
+		"sqlite missing reference": {
+			errors.New("constraint failed: FOREIGN KEY constraint failed (787)"),
+			domainErrors.ErrReference, "",
+		},
+		"sqlite null column": {
+			errors.New("NOT NULL constraint failed: users.name (1299)"),
+			domainErrors.ErrMissingValue, "users.name",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := translateError(tt.err)
+			var constraintErr *domainErrors.ConstraintError
+			if !errors.As(err, &constraintErr) {
+				t.Fatalf("err = %v, want a ConstraintError", err)
+			}
+			if !errors.Is(err, tt.kind) || constraintErr.Constraint != tt.constraint {
+				t.Errorf("got %v on %q, want %v on %q", constraintErr.Kind, constraintErr.Constraint, tt.kind, tt.constraint)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("the driver error is not kept")
+			}
+		})
+	}
+}
+
+func TestTranslateErrorKeepsOtherErrors(t *testing.T) {
+	translated := &domainErrors.ConstraintError{Kind: domainErrors.ErrDuplicate, Err: errors.New("duplicate")}
+	for name, err := range map[string]error{
+		"mysql unknown column": &mysql.MySQLError{Number: 1054, Message: "Unknown column 'foo' in 'field list'"},
+		"postgres syntax":      &pgError{Code: "42601", Message: `syntax error at or near "FORM"`},
+		"sqlite no table":      errors.New("no such table: users"),
+		"already translated":   translated,
+	} {
+		if got := translateError(err); got != err {
+			t.Errorf("%s: translateError = %v, want the error unchanged", name, got)
+		}
+	}
+	if translateError(nil) != nil {
+		t.Error("translateError(nil) is not nil")
+	}
+}
+
+func TestDuplicateUser(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want error
+	}{
+		"mysql email":     {&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'users.idx_users_live_email_index'"}, user.ErrEmailExists},
+		"mysql public id": {&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'users.idx_users_public_id'"}, user.ErrUserExists},
+		"postgres email":  {&pgError{Code: "23505", ConstraintName: "idx_users_live_email_index"}, user.ErrEmailExists},
+		"sqlite email":    {errors.New("UNIQUE constraint failed: users.email_index (2067)"), user.ErrEmailExists},
+		"sqlite id":       {errors.New("UNIQUE constraint failed: users.id (1555)"), user.ErrUserExists},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := duplicateUser(translateError(tt.err))
+			if !errors.Is(err, tt.want) || !errors.Is(err, domainErrors.ErrDuplicate) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Other errors are returned as they are
+	missing := translateError(&mysql.MySQLError{Number: 1048, Message: "Column 'email' cannot be null"})
+	if err := duplicateUser(missing); err != missing {
+		t.Errorf("err = %v, want the error unchanged", err)
+	}
+}
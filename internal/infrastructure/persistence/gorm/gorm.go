@@ -1,26 +1,121 @@
-package gorm
-
-import (
-	"go-clean-v3/internal/config"
-
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
-)
-
-func NewDB(cfg *config.Config) (*gorm.DB, error) {
-	dsn := cfg.DatabaseURL
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return nil, err
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, err
-	}
-
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-
-	return db, nil
-}
\ No newline at end of file
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/infrastructure/encryption"
+	"go-clean-v3/pkg/logger"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	connectInitialBackoff = 500 * time.Millisecond
+	connectMaxBackoff     = 10 * time.Second
+)
+
+// NewDB connects to the primary database, retrying with exponential backoff until
+// cfg.DatabaseConnectTimeout so the app can start before MySQL is ready.
+func NewDB(cfg *config.Config) (*gorm.DB, error) {
+	dsn := mysqlDSN(cfg.DatabaseURL)
+	db, err := openWithRetry(cfg.DatabaseConnectTimeout, func() (*gorm.DB, error) {
+		return gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	configurePool(sqlDB, cfg)
+
+	if err := registerWriteTracking(db); err != nil {
+		return nil, err
+	}
+	if err := db.Use(tenantScope{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(errorTranslator{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(queryMetrics{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(queryTracing{}); err != nil {
+		return nil, err
+	}
+
+	service, err := encryption.NewService(cfg.EncryptionKeys, cfg.EncryptionActiveKey, cfg.EncryptionIndexKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(fieldEncryption{service: service}); err != nil {
+		return nil, err
+	}
+
+	stats := &poolStats{primary: sqlDB}
+	if len(cfg.DatabaseReplicaURLs) > 0 {
+		if stats.replicas, stats.stopWatch, err = useReplicas(db, sqlDB, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := db.Use(stats); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// mysqlDSN accepts database urls with or without the mysql:// scheme that the
+// migration tooling uses
+func mysqlDSN(url string) string {
+	return strings.TrimPrefix(url, "mysql://")
+}
+
+// configurePool applies the connection pool settings from cfg
+func configurePool(sqlDB *sql.DB, cfg *config.Config) {
+	sqlDB.SetMaxIdleConns(cfg.DatabaseMaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.DatabaseMaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.DatabaseConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DatabaseConnMaxIdleTime)
+}
+
+// openWithRetry calls open until it succeeds or timeout has passed, waiting twice
+// as long (with jitter) after every failed attempt
+func openWithRetry(timeout time.Duration, open func() (*gorm.DB, error)) (*gorm.DB, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	backoff := connectInitialBackoff
+	for attempt := 1; ; attempt++ {
+		db, err := open()
+		if err == nil {
+			return db, nil
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		logger.Error("[Gorm-openWithRetry-1] Database not reachable, retrying", map[string]interface{}{
+			"attempt":  attempt,
+			"retry_in": wait.String(),
+			"error":    err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+
+		if backoff *= 2; backoff > connectMaxBackoff {
+			backoff = connectMaxBackoff
+		}
+	}
+}
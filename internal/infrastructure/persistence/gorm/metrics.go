@@ -0,0 +1,82 @@
+package gorm
+
+import (
+	"errors"
+	"go-clean-v3/pkg/metrics"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "app:query_start"
+
+// queryMetrics is a GORM plugin that records the duration and failures of every
+// statement in pkg/metrics, labeled by operation and table
+type queryMetrics struct{}
+
+func (queryMetrics) Name() string {
+	return "app:query_metrics"
+}
+
+func (queryMetrics) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("app:query_metrics_start", startQuery); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("app:query_metrics", observeQuery("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("app:query_metrics_start", startQuery); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("app:query_metrics", observeQuery("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("app:query_metrics_start", startQuery); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("app:query_metrics", observeQuery("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("app:query_metrics_start", startQuery); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("app:query_metrics", observeQuery("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("app:query_metrics_start", startQuery); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("app:query_metrics", observeQuery("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("app:query_metrics_start", startQuery); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("app:query_metrics", observeQuery("raw"))
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		// A missing record is an answer, not a failed query
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+		metrics.ObserveQuery(operation, table, time.Since(start), failed)
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type AuditLogModel struct {
+	ID         int64                  `gorm:"primaryKey;autoIncrement" json:"id"`
+	ActorID    *int64                 `gorm:"index" json:"actor_id"`
+	Action     string                 `gorm:"type:varchar(64);not null;index" json:"action"`
+	EntityType string                 `gorm:"type:varchar(64);not null;index:idx_audit_logs_entity" json:"entity_type"`
+	EntityID   string                 `gorm:"type:varchar(64);not null;index:idx_audit_logs_entity" json:"entity_id"`
+	IP         string                 `gorm:"type:varchar(45);not null;default:''" json:"ip"`
+	UserAgent  string                 `gorm:"type:varchar(255);not null;default:''" json:"user_agent"`
+	Before     map[string]interface{} `gorm:"type:json;serializer:json" json:"before"`
+	After      map[string]interface{} `gorm:"type:json;serializer:json" json:"after"`
+	CreatedAt  time.Time              `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (AuditLogModel) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,12 @@
+package models
+
+// All returns every model that is backed by a table created in migrations/
+func All() []interface{} {
+	return []interface{}{
+		&UserModel{},
+		&AuditLogModel{},
+		&WorkspaceModel{},
+		&WorkspaceMemberModel{},
+		&OutboxMessageModel{},
+	}
+}
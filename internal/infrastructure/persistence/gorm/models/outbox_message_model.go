@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type OutboxMessageModel struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement;index:idx_outbox_messages_aggregate,priority:3" json:"id"`
+	IdempotencyKey string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"idempotency_key"`
+	AggregateType  string     `gorm:"type:varchar(64);not null;index:idx_outbox_messages_aggregate,priority:1" json:"aggregate_type"`
+	AggregateID    string     `gorm:"type:varchar(64);not null;index:idx_outbox_messages_aggregate,priority:2" json:"aggregate_id"`
+	EventType      string     `gorm:"type:varchar(128);not null" json:"event_type"`
+	RequestID      string     `gorm:"type:varchar(128);not null;default:''" json:"request_id"`
+	Payload        string     `gorm:"type:json;not null" json:"payload"`
+	Attempts       int        `gorm:"size:32;not null;default:0" json:"attempts"`
+	LastError      string     `gorm:"type:varchar(1024);not null;default:''" json:"last_error"`
+	NextAttemptAt  time.Time  `gorm:"not null;index:idx_outbox_messages_pending,priority:3" json:"next_attempt_at"`
+	PublishedAt    *time.Time `gorm:"index:idx_outbox_messages_pending,priority:1" json:"published_at"`
+	DeadAt         *time.Time `gorm:"index:idx_outbox_messages_pending,priority:2" json:"dead_at"`
+	ClaimToken     string     `gorm:"type:varchar(64);not null;default:''" json:"-"`
+	ClaimedUntil   *time.Time `json:"claimed_until"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (OutboxMessageModel) TableName() string {
+	return "outbox_messages"
+}
@@ -1,12 +1,30 @@
-package models
-
-import "time"
-
-type UserModel struct {
-	ID        int64  `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name      string `gorm:"type:varchar(100);not null" json:"name"`
-	Email     string `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
-	Password  string `gorm:"type:varchar(255);not null" json:"-"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
-}
\ No newline at end of file
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserModel stores Email encrypted. EmailIndex is its blind index for lookups;
+// it is NULL for rows written before encryption until ReencryptUsers has run.
+// LiveEmailIndex is generated by the database and holds EmailIndex only while
+// the user is not deleted, so a deleted user does not hold on to their email.
+type UserModel struct {
+	ID             int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	PublicID       string         `gorm:"type:char(36);uniqueIndex;not null" json:"public_id"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name"`
+	Email          string         `gorm:"type:varchar(512);serializer:encrypted;not null" json:"email"`
+	EmailIndex     *string        `gorm:"type:char(64);index" json:"-"`
+	LiveEmailIndex *string        `gorm:"->;type:char(64) AS (CASE WHEN deleted_at IS NULL THEN email_index END) VIRTUAL;uniqueIndex" json:"-"`
+	Password       string         `gorm:"type:varchar(255);not null" json:"-"`
+	Role           string         `gorm:"type:varchar(20);not null;default:user" json:"role"`
+	Version        int64          `gorm:"not null;default:1" json:"version"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+func (UserModel) TableName() string {
+	return "users"
+}
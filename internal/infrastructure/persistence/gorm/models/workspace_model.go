@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type WorkspaceModel struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	PublicID  string    `gorm:"type:char(36);uniqueIndex;not null" json:"public_id"`
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (WorkspaceModel) TableName() string {
+	return "workspaces"
+}
+
+// WorkspaceMemberModel is tenant scoped through its workspace_id column
+type WorkspaceMemberModel struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	WorkspaceID int64     `gorm:"not null;uniqueIndex:idx_workspace_members_workspace_user" json:"workspace_id"`
+	UserID      int64     `gorm:"not null;uniqueIndex:idx_workspace_members_workspace_user;index" json:"user_id"`
+	Role        string    `gorm:"type:varchar(20);not null;default:member" json:"role"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// User is only loaded by ListMembers, for the public ID
+	User *UserModel `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (WorkspaceMemberModel) TableName() string {
+	return "workspace_members"
+}
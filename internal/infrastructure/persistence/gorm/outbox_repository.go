@@ -0,0 +1,177 @@
+package gorm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/internal/infrastructure/persistence/gorm/models"
+	"go-clean-v3/pkg/requestid"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxLastErrorLength = 1024
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func toMessageDomain(m *models.OutboxMessageModel) *event.Message {
+	return &event.Message{
+		ID:             m.ID,
+		IdempotencyKey: m.IdempotencyKey,
+		AggregateType:  m.AggregateType,
+		AggregateID:    m.AggregateID,
+		EventType:      m.EventType,
+		RequestID:      m.RequestID,
+		Payload:        json.RawMessage(m.Payload),
+		Attempts:       m.Attempts,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// randomKey returns a random hex key, such as the idempotency key of an event or
+// the token of a claim
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Record implements event.Recorder.
+func (o *outboxRepository) Record(ctx context.Context, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]*models.OutboxMessageModel, len(events))
+	for i, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		key, err := randomKey()
+		if err != nil {
+			return err
+		}
+
+		rows[i] = &models.OutboxMessageModel{
+			IdempotencyKey: key,
+			AggregateType:  e.AggregateType(),
+			AggregateID:    e.AggregateID(),
+			EventType:      e.EventName(),
+			RequestID:      requestid.FromContext(ctx),
+			Payload:        string(payload),
+			NextAttemptAt:  now,
+		}
+	}
+
+	return conn(ctx, o.db).Create(rows).Error
+}
+
+// ClaimPending implements event.OutboxRepositoryInterface.
+// Candidates are locked with SKIP LOCKED only while the claim is written, so
+// relays on other instances pick other aggregates without waiting.
+func (o *outboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*event.Message, error) {
+	token, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	until := now.Add(lease)
+
+	var rows []models.OutboxMessageModel
+	err = conn(ctx, o.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?", now).
+			Where("claimed_until IS NULL OR claimed_until <= ?", now).
+			// Only the oldest pending message of each aggregate, to keep them in order
+			Where(`NOT EXISTS (SELECT 1 FROM outbox_messages earlier
+				WHERE earlier.aggregate_type = outbox_messages.aggregate_type
+				AND earlier.aggregate_id = outbox_messages.aggregate_id
+				AND earlier.published_at IS NULL AND earlier.dead_at IS NULL
+				AND earlier.id < outbox_messages.id)`).
+			Order("id").
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+		}
+		return tx.Model(&models.OutboxMessageModel{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"claim_token": token, "claimed_until": until}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*event.Message, len(rows))
+	for i := range rows {
+		messages[i] = toMessageDomain(&rows[i])
+		messages[i].ClaimToken = token
+		messages[i].ClaimedUntil = until
+	}
+	return messages, nil
+}
+
+// settle applies updates to msg and releases its claim, unless another relay
+// has claimed it since
+func (o *outboxRepository) settle(ctx context.Context, msg *event.Message, updates map[string]interface{}) error {
+	updates["claim_token"] = ""
+	updates["claimed_until"] = nil
+
+	result := conn(ctx, o.db).Model(&models.OutboxMessageModel{}).
+		Where("id = ? AND claim_token = ?", msg.ID, msg.ClaimToken).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return event.ErrClaimLost
+	}
+	return nil
+}
+
+// MarkPublished implements event.OutboxRepositoryInterface.
+func (o *outboxRepository) MarkPublished(ctx context.Context, msg *event.Message) error {
+	return o.settle(ctx, msg, map[string]interface{}{
+		"published_at": time.Now(),
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   "",
+	})
+}
+
+// MarkFailed implements event.OutboxRepositoryInterface.
+func (o *outboxRepository) MarkFailed(ctx context.Context, msg *event.Message, cause error, next time.Time, dead bool) error {
+	lastError := cause.Error()
+	if len(lastError) > maxLastErrorLength {
+		lastError = lastError[:maxLastErrorLength]
+	}
+
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": next,
+	}
+	if dead {
+		updates["dead_at"] = time.Now()
+	}
+
+	return o.settle(ctx, msg, updates)
+}
+
+func NewOutboxRepository(db *gorm.DB) event.OutboxRepositoryInterface {
+	return &outboxRepository{db: db}
+}
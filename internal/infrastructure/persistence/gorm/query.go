@@ -0,0 +1,170 @@
+package gorm
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"go-clean-v3/internal/domain/query"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// columns maps the field names of a query.Schema to the SQL columns backing them
+type columns map[string]string
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// applyFilters translates spec filters into WHERE clauses
+func applyFilters(db *gorm.DB, filters []query.Filter, cols columns) *gorm.DB {
+	for _, f := range filters {
+		col := cols[f.Field]
+		switch f.Op {
+		case query.OpEq:
+			db = db.Where(col+" = ?", f.Value)
+		case query.OpNe:
+			db = db.Where(col+" <> ?", f.Value)
+		case query.OpGt:
+			db = db.Where(col+" > ?", f.Value)
+		case query.OpGte:
+			db = db.Where(col+" >= ?", f.Value)
+		case query.OpLt:
+			db = db.Where(col+" < ?", f.Value)
+		case query.OpLte:
+			db = db.Where(col+" <= ?", f.Value)
+		case query.OpLike:
+			db = db.Where(col+" LIKE ?", "%"+likeEscaper.Replace(f.Value.(string))+"%")
+		case query.OpIn:
+			db = db.Where(col+" IN ?", f.Value)
+		}
+	}
+	return db
+}
+
+// afterCursor restricts the query to rows that sort strictly after the cursor position.
+// MySQL sorts NULL before every other value, so NULLs come first in ascending order
+// and last in descending order; nullable reports which columns may hold them.
+func afterCursor(db *gorm.DB, sorts []query.Sort, values []interface{}, pk string, id int64, cols columns, nullable func(string) bool) *gorm.DB {
+	var (
+		clauses []string
+		args    []interface{}
+		equal   []string
+		eqArgs  []interface{}
+	)
+
+	for i, s := range sorts {
+		col := cols[s.Field]
+		if clause, clauseArgs, ok := beyond(col, s.Direction, values[i], nullable(col)); ok {
+			clauses = append(clauses, "("+strings.Join(append(append([]string(nil), equal...), clause), " AND ")+")")
+			args = append(append(args, eqArgs...), clauseArgs...)
+		}
+
+		if values[i] == nil {
+			equal = append(equal, col+" IS NULL")
+		} else {
+			equal = append(equal, col+" = ?")
+			eqArgs = append(eqArgs, values[i])
+		}
+	}
+	clauses = append(clauses, "("+strings.Join(append(equal, pk+" > ?"), " AND ")+")")
+	args = append(append(args, eqArgs...), id)
+
+	return db.Where(strings.Join(clauses, " OR "), args...)
+}
+
+// beyond returns the condition for values of col that sort strictly after value.
+// ok is false when nothing can, which is the case of NULL in descending order.
+func beyond(col string, direction query.Direction, value interface{}, nullable bool) (clause string, args []interface{}, ok bool) {
+	switch {
+	case value == nil && direction == query.Desc:
+		return "", nil, false
+	case value == nil:
+		return col + " IS NOT NULL", nil, true
+	case direction == query.Desc && nullable:
+		return "(" + col + " < ? OR " + col + " IS NULL)", []interface{}{value}, true
+	case direction == query.Desc:
+		return col + " < ?", []interface{}{value}, true
+	}
+	return col + " > ?", []interface{}{value}, true
+}
+
+// findPage runs a list query described by spec against the model M.
+// Rows are ordered by the requested sort followed by the primary key so pages are stable.
+// Cursors are sealed with the encryption service, as they hold that primary key.
+func findPage[M any](db *gorm.DB, spec query.Spec, schema query.Schema, cols columns) (*query.Page[M], error) {
+	if err := schema.Normalize(&spec); err != nil {
+		return nil, err
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(M)); err != nil {
+		return nil, err
+	}
+	pkField := stmt.Schema.PrioritizedPrimaryField
+	pk := pkField.DBName
+
+	db = applyFilters(db.Model(new(M)), spec.Filters, cols)
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	page := &query.Page[M]{Total: total, Limit: spec.Limit}
+	service := EncryptionService(db)
+	if spec.Cursor != "" {
+		raw, err := service.OpenToken(spec.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed cursor", query.ErrInvalidQuery)
+		}
+		spec.Cursor = string(raw)
+
+		values, id, err := schema.DecodeCursor(spec)
+		if err != nil {
+			return nil, err
+		}
+		nullable := func(col string) bool {
+			field := stmt.Schema.LookUpField(col)
+			return field == nil || (!field.NotNull && !field.PrimaryKey)
+		}
+		db = afterCursor(db, spec.Sort, values, pk, id, cols, nullable)
+	} else {
+		db = db.Offset(spec.Offset)
+		page.Offset = spec.Offset
+	}
+
+	for _, s := range spec.Sort {
+		db = db.Order(cols[s.Field] + " " + string(s.Direction))
+	}
+	db = db.Order(pk + " asc")
+
+	// Fetch one extra row to find out whether there is a next page
+	var rows []M
+	if err := db.Limit(spec.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) <= spec.Limit {
+		page.Items = rows
+		return page, nil
+	}
+
+	page.Items = rows[:spec.Limit]
+	last := reflect.ValueOf(&page.Items[spec.Limit-1]).Elem()
+	values := make([]interface{}, 0, len(spec.Sort))
+	for _, s := range spec.Sort {
+		v, _ := stmt.Schema.LookUpField(cols[s.Field]).ValueOf(db.Statement.Context, last)
+		if valuer, ok := v.(driver.Valuer); ok {
+			v, _ = valuer.Value()
+		}
+		values = append(values, v)
+	}
+	id, _ := pkField.ValueOf(db.Statement.Context, last)
+	cursor := query.EncodeCursor(spec.Sort, values, reflect.ValueOf(id).Int())
+	nextCursor, err := service.SealToken([]byte(cursor))
+	if err != nil {
+		return nil, err
+	}
+	page.NextCursor = nextCursor
+
+	return page, nil
+}
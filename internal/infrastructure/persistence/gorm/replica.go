@@ -0,0 +1,128 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/domain/transaction"
+	"go-clean-v3/pkg/logger"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// replicaPolicy spreads reads round-robin over the replicas that passed their last
+// health check and falls back to the primary when none did.
+type replicaPolicy struct {
+	primary gorm.ConnPool
+	healthy map[gorm.ConnPool]*atomic.Bool
+	next    atomic.Uint64
+}
+
+// Resolve implements dbresolver.Policy.
+func (p *replicaPolicy) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	n := uint64(len(pools))
+	for i := uint64(0); i < n; i++ {
+		pool := pools[p.next.Add(1)%n]
+		if healthy, ok := p.healthy[pool]; ok && healthy.Load() {
+			return pool
+		}
+	}
+	return p.primary
+}
+
+// watch pings every replica each interval and updates its health until ctx is done
+func (p *replicaPolicy) watch(ctx context.Context, replicas []*sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for i, replica := range replicas {
+			ctx, cancel := context.WithTimeout(ctx, interval/2)
+			err := replica.PingContext(ctx)
+			cancel()
+
+			healthy := p.healthy[replica]
+			if was := healthy.Swap(err == nil); was != (err == nil) {
+				if err != nil {
+					logger.Error("[Gorm-watch-1] Replica is unhealthy", map[string]interface{}{"replica": i, "error": err.Error()})
+				} else {
+					logger.Info("Replica is healthy again", map[string]interface{}{"replica": i})
+				}
+			}
+		}
+	}
+}
+
+// useReplicas routes reads of db to the replicas in cfg. Writes, transactions and
+// reads made with a transaction.ReadFromPrimary context stay on the primary. The
+// returned function stops the replica health checks.
+func useReplicas(db *gorm.DB, primary *sql.DB, cfg *config.Config) ([]*sql.DB, func(), error) {
+	if cfg.DatabaseReplicaHealthInterval <= 0 {
+		return nil, nil, fmt.Errorf("replica health interval must be positive, got %s", cfg.DatabaseReplicaHealthInterval)
+	}
+
+	policy := &replicaPolicy{
+		primary: primary,
+		healthy: map[gorm.ConnPool]*atomic.Bool{},
+	}
+
+	replicas := make([]*sql.DB, 0, len(cfg.DatabaseReplicaURLs))
+	dialectors := make([]gorm.Dialector, 0, len(cfg.DatabaseReplicaURLs)+1)
+	for _, dsn := range cfg.DatabaseReplicaURLs {
+		replica, err := sql.Open("mysql", mysqlDSN(dsn))
+		if err != nil {
+			return nil, nil, err
+		}
+		configurePool(replica, cfg)
+
+		healthy := &atomic.Bool{}
+		healthy.Store(replica.Ping() == nil)
+		policy.healthy[replica] = healthy
+
+		replicas = append(replicas, replica)
+		dialectors = append(dialectors, mysql.New(mysql.Config{Conn: replica}))
+	}
+	// The primary is listed last so the policy is consulted even with a single replica;
+	// it is only picked when no replica is healthy.
+	dialectors = append(dialectors, mysql.New(mysql.Config{Conn: primary}))
+
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   policy,
+	})); err != nil {
+		return nil, nil, err
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	go policy.watch(ctx, replicas, cfg.DatabaseReplicaHealthInterval)
+	return replicas, stop, nil
+}
+
+// registerWriteTracking marks the statement context as written after every
+// successful mutation, see transaction.WithReadYourWrites.
+func registerWriteTracking(db *gorm.DB) error {
+	markWritten := func(tx *gorm.DB) {
+		if tx.Error == nil && tx.Statement.Context != nil {
+			transaction.MarkWritten(tx.Statement.Context)
+		}
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("app:mark_written", markWritten); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("app:mark_written", markWritten); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("app:mark_written", markWritten)
+}
@@ -0,0 +1,70 @@
+package gorm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+const poolStatsName = "app:pool_stats"
+
+// poolStats is a GORM plugin that remembers every connection pool behind a DB
+type poolStats struct {
+	primary  *sql.DB
+	replicas []*sql.DB
+	// stopWatch stops the replica health checks, it is nil without replicas
+	stopWatch func()
+}
+
+// Name implements gorm.Plugin.
+func (p *poolStats) Name() string {
+	return poolStatsName
+}
+
+// Initialize implements gorm.Plugin.
+func (p *poolStats) Initialize(db *gorm.DB) error {
+	return nil
+}
+
+// Stats returns the connection pool statistics of the primary and every replica of db
+func Stats(db *gorm.DB) map[string]sql.DBStats {
+	stats := map[string]sql.DBStats{}
+
+	plugin, ok := db.Config.Plugins[poolStatsName].(*poolStats)
+	if !ok {
+		if sqlDB, err := db.DB(); err == nil {
+			stats["primary"] = sqlDB.Stats()
+		}
+		return stats
+	}
+
+	stats["primary"] = plugin.primary.Stats()
+	for i, replica := range plugin.replicas {
+		stats[fmt.Sprintf("replica_%d", i)] = replica.Stats()
+	}
+	return stats
+}
+
+// Close stops the replica health checks and closes every connection pool of db
+func Close(db *gorm.DB) error {
+	plugin, ok := db.Config.Plugins[poolStatsName].(*poolStats)
+	if !ok {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	}
+
+	if plugin.stopWatch != nil {
+		plugin.stopWatch()
+	}
+	errs := make([]error, 0, len(plugin.replicas)+1)
+	for _, replica := range plugin.replicas {
+		errs = append(errs, replica.Close())
+	}
+	errs = append(errs, plugin.primary.Close())
+	return errors.Join(errs...)
+}
@@ -0,0 +1,121 @@
+package gorm
+
+import (
+	"context"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/workspace"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const tenantColumn = "workspace_id"
+
+// tenantScope is a GORM plugin that confines every model with a workspace_id
+// column to the tenant in the statement's context. Queries, updates and deletes
+// get a workspace_id condition and creates get the tenant stamped on. Without a
+// tenant the statement fails with workspace.ErrNoTenant, unless the context was
+// marked with workspace.CrossTenant. Raw SQL is not scoped.
+type tenantScope struct{}
+
+func (tenantScope) Name() string {
+	return "app:tenant_scope"
+}
+
+func (tenantScope) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("app:tenant_scope", scopeToTenant); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("app:tenant_scope", scopeToTenant); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("app:tenant_scope", scopeToTenant); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("app:tenant_scope", scopeToTenant); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register("app:tenant_scope", stampTenant)
+}
+
+// tenantField returns the workspace_id field of the statement's model, if any
+func tenantField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(tenantColumn)
+}
+
+// currentTenant returns the tenant for the statement; ok is false when the
+// statement may run unscoped
+func currentTenant(db *gorm.DB) (id int64, ok bool) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if workspace.IsCrossTenant(ctx) {
+		return 0, false
+	}
+
+	id, found := workspace.TenantFromContext(ctx)
+	if !found {
+		db.AddError(workspace.ErrNoTenant)
+		return 0, false
+	}
+	return id, true
+}
+
+func scopeToTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+
+	id, ok := currentTenant(db)
+	if !ok {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id},
+	}})
+}
+
+func stampTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil {
+		return
+	}
+
+	id, ok := currentTenant(db)
+	if !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	rv := db.Statement.ReflectValue
+	stamp := func(row reflect.Value) {
+		current, zero := field.ValueOf(ctx, row)
+		if zero {
+			if err := field.Set(ctx, row, id); err != nil {
+				db.AddError(err)
+			}
+			return
+		}
+		if tenant, _ := current.(int64); tenant != id {
+			db.AddError(domainErrors.ErrForbidden)
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			stamp(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		stamp(rv)
+	}
+}
@@ -0,0 +1,103 @@
+package gorm
+
+import (
+	"errors"
+	"go-clean-v3/pkg/tracing"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const querySpanKey = "app:query_span"
+
+// queryTracing is a GORM plugin that wraps every statement in a client span,
+// child of the span in the statement context. Only the SQL with placeholders is
+// recorded, never the bound values.
+type queryTracing struct{}
+
+func (queryTracing) Name() string {
+	return "app:query_tracing"
+}
+
+func (queryTracing) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("app:query_tracing_start", startSpan("create")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("app:query_tracing", endSpan("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("app:query_tracing_start", startSpan("query")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("app:query_tracing", endSpan("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("app:query_tracing_start", startSpan("update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("app:query_tracing", endSpan("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("app:query_tracing_start", startSpan("delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("app:query_tracing", endSpan("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("app:query_tracing_start", startSpan("row")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("app:query_tracing", endSpan("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("app:query_tracing_start", startSpan("raw")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("app:query_tracing", endSpan("raw"))
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+
+		ctx, span := tracing.Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameMySQL,
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(querySpanKey, span)
+	}
+}
+
+func endSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(querySpanKey)
+		if !ok {
+			return
+		}
+		span, ok := v.(trace.Span)
+		if !ok {
+			return
+		}
+
+		span.SetAttributes(semconv.DBQueryText(db.Statement.SQL.String()))
+		// A missing record is an answer, not a failed query
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(db.Error)
+			span.SetStatus(codes.Error, db.Error.Error())
+		} else if operation == "query" {
+			span.SetAttributes(semconv.DBResponseReturnedRows(int(db.RowsAffected)))
+		}
+		span.End()
+	}
+}
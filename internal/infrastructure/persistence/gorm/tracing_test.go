@@ -0,0 +1,80 @@
+package gorm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type tracedRow struct {
+	ID    int64
+	Email string
+}
+
+// newDryRunDB builds SQL for MySQL without connecting to it
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(localhost:3306)/test", SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(queryTracing{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestQuerySpansLeaveOutBoundValues(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db := newDryRunDB(t)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+
+	var rows []tracedRow
+	db.WithContext(ctx).Where("email = ?", "alice@example.com").Find(&rows)
+	if err := db.WithContext(ctx).Model(&tracedRow{ID: 7}).Update("email", "bob@example.com").Error; err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(spans))
+	}
+	for i, name := range []string{"gorm.query traced_rows", "gorm.update traced_rows"} {
+		span := spans[i]
+		if span.Name() != name {
+			t.Errorf("span %d = %q, want %q", i, span.Name(), name)
+		}
+		if span.SpanKind() != trace.SpanKindClient || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("%s is not a client span under the request", span.Name())
+		}
+
+		var text string
+		for _, kv := range span.Attributes() {
+			if kv.Key == "db.query.text" {
+				text = kv.Value.AsString()
+			}
+		}
+		if !strings.Contains(text, "?") {
+			t.Errorf("%s: db.query.text = %q, want the SQL with placeholders", span.Name(), text)
+		}
+		if strings.Contains(text, "example.com") || strings.Contains(text, "7") {
+			t.Errorf("%s: db.query.text %q holds a bound value", span.Name(), text)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package gorm
+
+import (
+	"context"
+	"go-clean-v3/internal/domain/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+type txKey struct{}
+
+type transactionManager struct {
+	db *gorm.DB
+}
+
+func NewTransactionManager(db *gorm.DB) transaction.Manager {
+	return &transactionManager{db: db}
+}
+
+// WithinTransaction implements transaction.Manager.
+// Nested calls reuse the outer transaction.
+func (t *transactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	ctx, runHooks := transaction.WithCommitHooks(ctx)
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	if err != nil {
+		// Statement errors are translated already, this catches failed commits
+		return translateError(err)
+	}
+
+	runHooks()
+	return nil
+}
+
+// conn returns the transaction stored in ctx, or db, bound to ctx so callbacks see its values.
+// Transactions always run on the primary; other reads go to a replica unless
+// ctx asks for the primary.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	db = db.WithContext(ctx)
+	if transaction.ReadFromPrimary(ctx) {
+		db = db.Clauses(dbresolver.Write)
+	}
+	return db
+}
@@ -1,77 +1,266 @@
-package gorm
-
-import (
-	"go-clean-v3/internal/domain/user"
-	"go-clean-v3/internal/infrastructure/persistence/gorm/models"
-
-	"gorm.io/gorm"
-)
-
-type userRepository struct {
-	db *gorm.DB
-}
-
-// toUserModel converts domain User to GORM model
-func toUserModel(u *user.User) *models.UserModel {
-	return &models.UserModel{
-		ID:       u.ID,
-		Name:     u.Name,
-		Email:    u.Email,
-		Password: u.Password,
-	}
-}
-
-// toUserDomain converts GORM model to domain User
-func toUserDomain(m *models.UserModel) *user.User {
-	return &user.User{
-		ID:       m.ID,
-		Name:     m.Name,
-		Email:    m.Email,
-		Password: m.Password,
-	}
-}
-
-// Create implements user.UserRepositoryInterface.
-func (u *userRepository) Create(user *user.User) error {
-	return u.db.Create(toUserModel(user)).Error
-}
-
-// Delete implements user.UserRepositoryInterface.
-func (u *userRepository) Delete(id int64) error {
-	return u.db.Delete(&models.UserModel{}, "id = ?", id).Error
-}
-
-// GetByEmail implements user.UserRepositoryInterface.
-func (u *userRepository) GetByEmail(email string) (*user.User, error) {
-	var model models.UserModel
-	if err := u.db.Where("email = ?", email).First(&model).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, user.ErrUserNotFound
-		}
-		return nil, err
-	}
-
-	return toUserDomain(&model), nil
-}
-
-// GetByID implements user.UserRepositoryInterface.
-func (u *userRepository) GetByID(id int64) (*user.User, error) {
-	var model models.UserModel
-	if err := u.db.First(&model, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, user.ErrUserNotFound
-		}
-		return nil, err
-	}
-
-	return toUserDomain(&model), nil
-}	
-
-// Update implements user.UserRepositoryInterface.
-func (u *userRepository) Update(user *user.User) error {
-	return u.db.Save(toUserModel(user)).Error
-}
-
-func NewUserRepository(db *gorm.DB) user.UserRepositoryInterface {
-	return &userRepository{db: db}
-}
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/infrastructure/encryption"
+	"go-clean-v3/internal/infrastructure/persistence/gorm/models"
+	"go-clean-v3/pkg/publicid"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userRepository struct {
+	db         *gorm.DB
+	encryption *encryption.Service
+}
+
+var userColumns = columns{
+	"id":         "public_id",
+	"name":       "name",
+	"email":      "email_index",
+	"role":       "role",
+	"created_at": "created_at",
+	"deleted_at": "deleted_at",
+}
+
+// toUserModel converts domain User to GORM model
+func (u *userRepository) toUserModel(usr *user.User) *models.UserModel {
+	emailIndex := u.encryption.BlindIndex(usr.Email)
+	return &models.UserModel{
+		ID:         usr.ID,
+		PublicID:   usr.PublicID,
+		Name:       usr.Name,
+		Email:      usr.Email,
+		EmailIndex: &emailIndex,
+		Password:   usr.Password,
+		Role:       usr.Role,
+		Version:    usr.Version,
+	}
+}
+
+// indexEmailFilters replaces email values in filters by the blind index of the
+// normalized email, which is what the email field is backed by
+func (u *userRepository) indexEmailFilters(spec query.Spec) (query.Spec, error) {
+	filters := make([]query.Filter, len(spec.Filters))
+	for i, f := range spec.Filters {
+		if f.Field == "email" {
+			switch v := f.Value.(type) {
+			case string:
+				f.Value = u.encryption.BlindIndex(user.NormalizeEmail(v))
+			case []interface{}:
+				indexes := make([]interface{}, len(v))
+				for j, value := range v {
+					email, ok := value.(string)
+					if !ok {
+						return spec, fmt.Errorf("%w: email filter values must be strings, got %T", query.ErrInvalidQuery, value)
+					}
+					indexes[j] = u.encryption.BlindIndex(user.NormalizeEmail(email))
+				}
+				f.Value = indexes
+			default:
+				return spec, fmt.Errorf("%w: email filter values must be strings, got %T", query.ErrInvalidQuery, v)
+			}
+		}
+		filters[i] = f
+	}
+	spec.Filters = filters
+	return spec, nil
+}
+
+// duplicateUser tells a taken email apart from other duplicate keys
+func duplicateUser(err error) error {
+	var constraintErr *domainErrors.ConstraintError
+	if errors.As(err, &constraintErr) && strings.Contains(constraintErr.Constraint, "email") {
+		return duplicateAs(err, user.ErrEmailExists)
+	}
+	return duplicateAs(err, user.ErrUserExists)
+}
+
+// toUserDomain converts GORM model to domain User
+func toUserDomain(m *models.UserModel) *user.User {
+	u := &user.User{
+		ID:        m.ID,
+		PublicID:  m.PublicID,
+		Name:      m.Name,
+		Email:     m.Email,
+		Password:  m.Password,
+		Role:      m.Role,
+		Version:   m.Version,
+		CreatedAt: m.CreatedAt,
+	}
+	if m.DeletedAt.Valid {
+		deletedAt := m.DeletedAt.Time
+		u.DeletedAt = &deletedAt
+	}
+	return u
+}
+
+// Create implements user.UserRepositoryInterface.
+func (u *userRepository) Create(ctx context.Context, user *user.User) error {
+	model := u.toUserModel(user)
+	model.Version = 1
+	if model.PublicID == "" {
+		model.PublicID = publicid.New()
+	}
+	if err := conn(ctx, u.db).Create(model).Error; err != nil {
+		return duplicateUser(err)
+	}
+
+	// Hand the generated values back to the caller
+	user.ID = model.ID
+	user.PublicID = model.PublicID
+	user.Version = model.Version
+	user.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// Delete implements user.UserRepositoryInterface.
+// Rows are only marked as deleted; PurgeDeletedBefore removes them for good.
+func (u *userRepository) Delete(ctx context.Context, id int64) error {
+	result := conn(ctx, u.db).Delete(&models.UserModel{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+	return nil
+}
+
+// GetByEmail implements user.UserRepositoryInterface.
+// Rows that have no blind index yet still hold the email in plaintext.
+func (u *userRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	var model models.UserModel
+	err := conn(ctx, u.db).
+		Where("email_index = ? OR (email_index IS NULL AND email = ?)", u.encryption.BlindIndex(email), email).
+		First(&model).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return toUserDomain(&model), nil
+}
+
+// GetByID implements user.UserRepositoryInterface.
+func (u *userRepository) GetByID(ctx context.Context, id int64) (*user.User, error) {
+	var model models.UserModel
+	if err := conn(ctx, u.db).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return toUserDomain(&model), nil
+}
+
+// GetByPublicID implements user.UserRepositoryInterface.
+func (u *userRepository) GetByPublicID(ctx context.Context, publicID string) (*user.User, error) {
+	var model models.UserModel
+	if err := conn(ctx, u.db).Where("public_id = ?", publicID).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return toUserDomain(&model), nil
+}
+
+// Update implements user.UserRepositoryInterface.
+// The row is only written when its version still matches user.Version,
+// otherwise domainErrors.ErrConflict is returned.
+func (u *userRepository) Update(ctx context.Context, usr *user.User) error {
+	// Updating from the model rather than a map, as only then GORM encrypts Email
+	model := u.toUserModel(usr)
+	model.Version = usr.Version + 1
+	columns := []string{"name", "email", "email_index", "role", "version", "updated_at"}
+	// Users read through a cache carry no password hash, which must not wipe the stored one
+	if usr.Password != "" {
+		columns = append(columns, "password")
+	}
+	result := conn(ctx, u.db).Model(&models.UserModel{}).
+		Where("id = ? AND version = ?", usr.ID, usr.Version).
+		Select(columns).
+		Updates(model)
+	if result.Error != nil {
+		return duplicateUser(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		if _, err := u.GetByID(ctx, usr.ID); err != nil {
+			return err
+		}
+		return domainErrors.ErrConflict
+	}
+
+	usr.Version++
+	return nil
+}
+
+// List implements user.UserRepositoryInterface.
+func (u *userRepository) List(ctx context.Context, spec query.Spec) (*query.Page[*user.User], error) {
+	spec, err := u.indexEmailFilters(spec)
+	if err != nil {
+		return nil, err
+	}
+	page, err := findPage[models.UserModel](conn(ctx, u.db), spec, user.ListSchema, userColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.MapPage(page, func(m models.UserModel) *user.User { return toUserDomain(&m) }), nil
+}
+
+// ListDeleted implements user.UserRepositoryInterface.
+func (u *userRepository) ListDeleted(ctx context.Context, spec query.Spec) (*query.Page[*user.User], error) {
+	spec, err := u.indexEmailFilters(spec)
+	if err != nil {
+		return nil, err
+	}
+	db := conn(ctx, u.db).Unscoped().Where("deleted_at IS NOT NULL")
+	page, err := findPage[models.UserModel](db, spec, user.DeletedListSchema, userColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.MapPage(page, func(m models.UserModel) *user.User { return toUserDomain(&m) }), nil
+}
+
+// Restore implements user.UserRepositoryInterface.
+func (u *userRepository) Restore(ctx context.Context, id int64) error {
+	result := conn(ctx, u.db).Unscoped().
+		Model(&models.UserModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		// A live user may have taken the email in the meantime
+		return duplicateUser(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return user.ErrUserNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedBefore implements user.UserRepositoryInterface.
+func (u *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, u.db).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&models.UserModel{})
+	return result.RowsAffected, result.Error
+}
+
+func NewUserRepository(db *gorm.DB) user.UserRepositoryInterface {
+	return &userRepository{db: db, encryption: EncryptionService(db)}
+}
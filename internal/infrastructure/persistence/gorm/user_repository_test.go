@@ -0,0 +1,56 @@
+package gorm
+
+import (
+	"encoding/base64"
+	"errors"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/infrastructure/encryption"
+	"strings"
+	"testing"
+)
+
+func newTestUserRepository(t *testing.T) *userRepository {
+	t.Helper()
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	service, err := encryption.NewService("a:"+key, "a", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &userRepository{encryption: service}
+}
+
+func TestIndexEmailFiltersNormalizesEmails(t *testing.T) {
+	repo := newTestUserRepository(t)
+	want := repo.encryption.BlindIndex("alice@example.com")
+
+	spec, err := repo.indexEmailFilters(query.Spec{Filters: []query.Filter{
+		{Field: "email", Op: query.OpEq, Value: " Alice@Example.com"},
+		{Field: "email", Op: query.OpIn, Value: []interface{}{"ALICE@example.com"}},
+		{Field: "name", Op: query.OpEq, Value: "Alice"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := spec.Filters[0].Value; got != want {
+		t.Errorf("eq value = %v, want the index of the normalized email", got)
+	}
+	if got := spec.Filters[1].Value.([]interface{})[0]; got != want {
+		t.Errorf("in value = %v, want the index of the normalized email", got)
+	}
+	if got := spec.Filters[2].Value; got != "Alice" {
+		t.Errorf("name value = %v, want it unchanged", got)
+	}
+}
+
+func TestIndexEmailFiltersRejectsNonStrings(t *testing.T) {
+	repo := newTestUserRepository(t)
+	for name, value := range map[string]interface{}{
+		"number":     42,
+		"mixed list": []interface{}{"alice@example.com", 42},
+	} {
+		_, err := repo.indexEmailFilters(query.Spec{Filters: []query.Filter{{Field: "email", Op: query.OpIn, Value: value}}})
+		if !errors.Is(err, query.ErrInvalidQuery) {
+			t.Errorf("%s: err = %v, want ErrInvalidQuery", name, err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/workspace"
+	"go-clean-v3/internal/infrastructure/persistence/gorm/models"
+	"go-clean-v3/pkg/publicid"
+
+	"gorm.io/gorm"
+)
+
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+var memberColumns = columns{
+	"id":         "id",
+	"user_id":    "user_id",
+	"role":       "role",
+	"created_at": "created_at",
+}
+
+func toWorkspaceDomain(m *models.WorkspaceModel) *workspace.Workspace {
+	return &workspace.Workspace{
+		ID:        m.ID,
+		PublicID:  m.PublicID,
+		Name:      m.Name,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func toMembershipDomain(m *models.WorkspaceMemberModel) *workspace.Membership {
+	membership := &workspace.Membership{
+		ID:          m.ID,
+		WorkspaceID: m.WorkspaceID,
+		UserID:      m.UserID,
+		Role:        m.Role,
+		CreatedAt:   m.CreatedAt,
+	}
+	if m.User != nil {
+		membership.UserPublicID = m.User.PublicID
+	}
+	return membership
+}
+
+// resolveUserFilters replaces the public user IDs in user_id filters by the
+// internal ones the column holds. IDs of unknown users become 0, which no row has.
+func (w *workspaceRepository) resolveUserFilters(ctx context.Context, spec query.Spec) (query.Spec, error) {
+	filters := make([]query.Filter, len(spec.Filters))
+	for i, f := range spec.Filters {
+		if f.Field == "user_id" {
+			var publicIDs []interface{}
+			switch v := f.Value.(type) {
+			case string:
+				publicIDs = []interface{}{v}
+			case []interface{}:
+				publicIDs = v
+			}
+
+			var users []models.UserModel
+			err := conn(ctx, w.db).Unscoped().Select("id", "public_id").Where("public_id IN ?", publicIDs).Find(&users).Error
+			if err != nil {
+				return spec, err
+			}
+			ids := map[string]int64{}
+			for _, u := range users {
+				ids[u.PublicID] = u.ID
+			}
+
+			resolved := make([]interface{}, len(publicIDs))
+			for j, publicID := range publicIDs {
+				resolved[j] = ids[publicID.(string)]
+			}
+			if _, ok := f.Value.(string); ok {
+				f.Value = resolved[0]
+			} else {
+				f.Value = resolved
+			}
+		}
+		filters[i] = f
+	}
+	spec.Filters = filters
+	return spec, nil
+}
+
+// Create implements workspace.WorkspaceRepositoryInterface.
+func (w *workspaceRepository) Create(ctx context.Context, ws *workspace.Workspace) error {
+	model := &models.WorkspaceModel{PublicID: ws.PublicID, Name: ws.Name}
+	if model.PublicID == "" {
+		model.PublicID = publicid.New()
+	}
+	if err := conn(ctx, w.db).Create(model).Error; err != nil {
+		return err
+	}
+
+	ws.ID = model.ID
+	ws.PublicID = model.PublicID
+	ws.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// GetByID implements workspace.WorkspaceRepositoryInterface.
+func (w *workspaceRepository) GetByID(ctx context.Context, id int64) (*workspace.Workspace, error) {
+	var model models.WorkspaceModel
+	if err := conn(ctx, w.db).First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, workspace.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	return toWorkspaceDomain(&model), nil
+}
+
+// GetByPublicID implements workspace.WorkspaceRepositoryInterface.
+func (w *workspaceRepository) GetByPublicID(ctx context.Context, publicID string) (*workspace.Workspace, error) {
+	var model models.WorkspaceModel
+	if err := conn(ctx, w.db).Where("public_id = ?", publicID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, workspace.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	return toWorkspaceDomain(&model), nil
+}
+
+// ListForUser implements workspace.WorkspaceRepositoryInterface.
+func (w *workspaceRepository) ListForUser(ctx context.Context, userID int64) ([]*workspace.Workspace, error) {
+	var list []models.WorkspaceModel
+	err := conn(ctx, w.db).
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.id").
+		Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]*workspace.Workspace, len(list))
+	for i := range list {
+		workspaces[i] = toWorkspaceDomain(&list[i])
+	}
+	return workspaces, nil
+}
+
+// AddMember implements workspace.WorkspaceRepositoryInterface.
+// The workspace is taken from the tenant in ctx.
+func (w *workspaceRepository) AddMember(ctx context.Context, m *workspace.Membership) error {
+	model := &models.WorkspaceMemberModel{
+		WorkspaceID: m.WorkspaceID,
+		UserID:      m.UserID,
+		Role:        m.Role,
+	}
+	if err := conn(ctx, w.db).Create(model).Error; err != nil {
+		return duplicateAs(err, workspace.ErrAlreadyMember)
+	}
+
+	m.ID = model.ID
+	m.WorkspaceID = model.WorkspaceID
+	m.CreatedAt = model.CreatedAt
+	return nil
+}
+
+// GetMember implements workspace.WorkspaceRepositoryInterface.
+func (w *workspaceRepository) GetMember(ctx context.Context, userID int64) (*workspace.Membership, error) {
+	var model models.WorkspaceMemberModel
+	if err := conn(ctx, w.db).Where("user_id = ?", userID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, workspace.ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	return toMembershipDomain(&model), nil
+}
+
+// ListMembers implements workspace.WorkspaceRepositoryInterface.
+// Members that deleted their account are still listed.
+func (w *workspaceRepository) ListMembers(ctx context.Context, spec query.Spec) (*query.Page[*workspace.Membership], error) {
+	spec, err := w.resolveUserFilters(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	db := conn(ctx, w.db).Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped().Select("id", "public_id")
+	})
+	page, err := findPage[models.WorkspaceMemberModel](db, spec, workspace.MemberListSchema, memberColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.MapPage(page, func(m models.WorkspaceMemberModel) *workspace.Membership { return toMembershipDomain(&m) }), nil
+}
+
+func NewWorkspaceRepository(db *gorm.DB) workspace.WorkspaceRepositoryInterface {
+	return &workspaceRepository{db: db}
+}
@@ -0,0 +1,68 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// EachBatch walks the id column of table in ascending order and calls fn with at
+// most size ids at a time, so Go migrations can process large tables in bounded
+// chunks instead of loading every row at once
+func EachBatch(ctx context.Context, tx *sql.Tx, table string, size int, fn func(ids []int64) error) error {
+	if size <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", size)
+	}
+
+	query := fmt.Sprintf("SELECT id FROM `%s` WHERE id > ? ORDER BY id LIMIT ?", table)
+	var last int64
+	for {
+		ids, err := batchIDs(ctx, tx, query, last, size)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := fn(ids); err != nil {
+			return err
+		}
+		if len(ids) < size {
+			return nil
+		}
+		last = ids[len(ids)-1]
+	}
+}
+
+func batchIDs(ctx context.Context, tx *sql.Tx, query string, after int64, size int) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, after, size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0, size)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// InArgs returns "?, ?, ..." and the matching arguments for an IN clause over ids
+func InArgs(ids []int64) (string, []interface{}) {
+	placeholders := make([]byte, 0, len(ids)*3)
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		if i > 0 {
+			placeholders = append(placeholders, ", "...)
+		}
+		placeholders = append(placeholders, '?')
+		args[i] = id
+	}
+	return string(placeholders), args
+}
@@ -0,0 +1,53 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	migrationFile = regexp.MustCompile(`^(\d+)_.+\.(up\.sql|down\.sql|go)$`)
+	invalidName   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Create writes an empty up/down migration pair to dir, numbered after the
+// highest existing SQL or Go migration. The files are embedded on the next build.
+func Create(dir string, name string) ([]string, error) {
+	name = strings.Trim(invalidName.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	next := uint64(1)
+	for _, entry := range entries {
+		m := migrationFile.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		if version, err := strconv.ParseUint(m[1], 10, 64); err == nil && version >= next {
+			next = version + 1
+		}
+	}
+
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", next, name, direction))
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return files, err
+		}
+		f.Close()
+		files = append(files, path)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-migrate/migrate/v4/database"
+)
+
+// GoFunc is the body of a Go migration. It runs inside tx, which is committed
+// when it returns nil and rolled back otherwise.
+type GoFunc func(ctx context.Context, tx *sql.Tx) error
+
+type goMigration struct {
+	version uint
+	name    string
+	up      GoFunc
+	down    GoFunc
+}
+
+var (
+	goMigrationsMu sync.RWMutex
+	goMigrations   = map[uint]goMigration{}
+)
+
+// Register adds a Go migration for changes that cannot be written as SQL, such as
+// backfills that need application code. It runs in version order together with the
+// SQL files and is recorded in the same schema_migrations table. down may be nil
+// when the migration cannot be reversed. Register is meant to be called from init
+// and panics on a duplicate version.
+func Register(version uint, name string, up GoFunc, down GoFunc) {
+	goMigrationsMu.Lock()
+	defer goMigrationsMu.Unlock()
+
+	if up == nil {
+		panic(fmt.Sprintf("migrate: Go migration %d has no up function", version))
+	}
+	if _, dup := goMigrations[version]; dup {
+		panic(fmt.Sprintf("migrate: Go migration %d registered twice", version))
+	}
+	goMigrations[version] = goMigration{version: version, name: name, up: up, down: down}
+}
+
+func registeredGoMigrations() map[uint]goMigration {
+	goMigrationsMu.RLock()
+	defer goMigrationsMu.RUnlock()
+
+	copied := make(map[uint]goMigration, len(goMigrations))
+	for version, m := range goMigrations {
+		copied[version] = m
+	}
+	return copied
+}
+
+// goMarker is the body the source hands out for Go migrations; goRunner
+// recognises it and calls the registered function instead of executing SQL
+const goMarker = "-- go migration "
+
+func goBody(version uint, direction string) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(fmt.Sprintf("%s%d %s", goMarker, version, direction)))
+}
+
+// goRunner wraps the SQL database driver and runs Go migrations in a transaction
+type goRunner struct {
+	database.Driver
+	db         *sql.DB
+	migrations map[uint]goMigration
+}
+
+func (r *goRunner) Run(migration io.Reader) error {
+	body, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(body, []byte(goMarker)) {
+		return r.Driver.Run(bytes.NewReader(body))
+	}
+
+	var (
+		version   uint
+		direction string
+	)
+	if _, err := fmt.Sscanf(string(body[len(goMarker):]), "%d %s", &version, &direction); err != nil {
+		return fmt.Errorf("invalid Go migration marker %q: %w", body, err)
+	}
+
+	m, ok := r.migrations[version]
+	if !ok {
+		return fmt.Errorf("Go migration %d is not registered", version)
+	}
+	fn := m.up
+	if direction == "down" {
+		fn = m.down
+	}
+
+	ctx := context.Background()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(ctx, tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Go migration %d_%s %s: %w", m.version, m.name, direction, err)
+	}
+	return tx.Commit()
+}
+
+func sortedVersions(migrations map[uint]goMigration) []uint {
+	versions := make([]uint, 0, len(migrations))
+	for version := range migrations {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
@@ -1,36 +1,171 @@
-package migrate
-
-import (
-	"database/sql"
-	"fmt"
-	"log"
-
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-)
-
-func Run(db *sql.DB, migrationPath string) error {
-	driver, err := mysql.WithInstance(db, &mysql.Config{})
-	if err != nil {
-		return fmt.Errorf("could not create migration driver: %w", err)
-	}
-
-	// Create migrate instnace with file source and MySQL driver
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://"+migrationPath,
-		"mysql",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("could not create migrate instance: %w", err)
-	}
-
-	// Run the migrations
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("migration failed: %w", err)
-	}
-
-	log.Print("Database migration completed successfully")
-	return nil
-}
\ No newline at end of file
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-clean-v3/pkg/logger"
+	"io/fs"
+	"strings"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies the SQL files and the registered Go migrations to a MySQL database
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+}
+
+// Status describes a single migration and whether it has been applied
+type Status struct {
+	Version uint
+	Name    string
+	Applied bool
+	Dirty   bool
+}
+
+// New connects to the database at dsn and reads the SQL migrations from files,
+// usually migrations.FS. Migrations hold several statements, so they get their own
+// connection with multiStatements enabled instead of sharing the application's pool.
+func New(dsn string, files fs.FS) (*Migrator, error) {
+	cfg, err := mysqlDriver.ParseDSN(strings.TrimPrefix(dsn, "mysql://"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid database url: %w", err)
+	}
+	cfg.MultiStatements = true
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %w", err)
+	}
+
+	driver, err := mysql.WithInstance(db, &mysql.Config{})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create migration driver: %w", err)
+	}
+
+	sqlSource, err := iofs.New(files, ".")
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("could not read migrations: %w", err)
+	}
+
+	goMigrations := registeredGoMigrations()
+	src, err := newMergedSource(sqlSource, goMigrations)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	// Create migrate instnace with the merged source and a MySQL driver that also runs Go migrations
+	m, err := migrate.NewWithInstance("go-clean", src, "mysql", &goRunner{Driver: driver, db: db, migrations: goMigrations})
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("could not create migrate instance: %w", err)
+	}
+
+	return &Migrator{m: m, source: src}, nil
+}
+
+// Close releases the migration connection
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// Up applies every pending migration
+func (mg *Migrator) Up() error {
+	return ignoreNoChange(mg.m.Up())
+}
+
+// Down rolls back the last steps migrations
+func (mg *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	return ignoreNoChange(mg.m.Steps(-steps))
+}
+
+// Goto migrates up or down to version
+func (mg *Migrator) Goto(version uint) error {
+	return ignoreNoChange(mg.m.Migrate(version))
+}
+
+// Force sets the recorded version without running migrations and clears the dirty flag.
+// Use it to recover after a migration failed halfway; -1 means no version.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// Version returns the current version; ok is false when no migration was applied yet
+func (mg *Migrator) Version() (version uint, dirty bool, ok bool, err error) {
+	version, dirty, err = mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, false, nil
+	}
+	if err != nil {
+		return 0, false, false, err
+	}
+	return version, dirty, true, nil
+}
+
+// Status lists every known migration in version order
+func (mg *Migrator) Status() ([]Status, error) {
+	current, dirty, ok, err := mg.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	version, err := mg.source.First()
+	for err == nil {
+		name := ""
+		if r, identifier, readErr := mg.source.ReadUp(version); readErr == nil {
+			r.Close()
+			name = identifier
+		}
+
+		statuses = append(statuses, Status{
+			Version: version,
+			Name:    name,
+			Applied: ok && version <= current,
+			Dirty:   ok && dirty && version == current,
+		})
+		version, err = mg.source.Next(version)
+	}
+	if !isNotExist(err) {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// Run applies every pending migration from files to the database at dsn
+func Run(dsn string, files fs.FS) error {
+	mg, err := New(dsn, files)
+	if err != nil {
+		return err
+	}
+	defer mg.Close()
+
+	// Run the migrations
+	if err := mg.Up(); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	logger.Info("Database migration completed successfully", nil)
+	return nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
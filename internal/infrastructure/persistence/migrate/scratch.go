@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+)
+
+// Scratch creates an empty database on the server at dsn, named after the database
+// in dsn and label. It returns the new database's dsn and a function that drops it.
+// The database named in dsn itself is never touched.
+func Scratch(dsn string, label string) (string, func() error, error) {
+	cfg, err := mysqlDriver.ParseDSN(strings.TrimPrefix(dsn, "mysql://"))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid database url: %w", err)
+	}
+
+	name := fmt.Sprintf("%s_%d", label, time.Now().UnixNano())
+	if cfg.DBName != "" {
+		name = cfg.DBName + "_" + name
+	}
+
+	server := cfg.Clone()
+	server.DBName = ""
+	admin, err := sql.Open("mysql", server.FormatDSN())
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := admin.Exec("CREATE DATABASE `" + name + "`"); err != nil {
+		admin.Close()
+		return "", nil, fmt.Errorf("could not create scratch database: %w", err)
+	}
+
+	drop := func() error {
+		defer admin.Close()
+		_, err := admin.Exec("DROP DATABASE IF EXISTS `" + name + "`")
+		return err
+	}
+
+	target := cfg.Clone()
+	target.DBName = name
+	return target.FormatDSN(), drop, nil
+}
@@ -0,0 +1,106 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// mergedSource serves the SQL files and the registered Go migrations as one
+// ordered list of versions
+type mergedSource struct {
+	sql        source.Driver
+	migrations map[uint]goMigration
+	versions   []uint
+}
+
+func newMergedSource(sqlSource source.Driver, migrations map[uint]goMigration) (*mergedSource, error) {
+	seen := make(map[uint]bool)
+	version, err := sqlSource.First()
+	for err == nil {
+		if _, clash := migrations[version]; clash {
+			return nil, fmt.Errorf("version %d exists both as SQL and as Go migration", version)
+		}
+		seen[version] = true
+		version, err = sqlSource.Next(version)
+	}
+	if !isNotExist(err) {
+		return nil, err
+	}
+
+	for _, version := range sortedVersions(migrations) {
+		seen[version] = true
+	}
+	versions := make([]uint, 0, len(seen))
+	for version := range seen {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	return &mergedSource{sql: sqlSource, migrations: migrations, versions: versions}, nil
+}
+
+func (s *mergedSource) Open(url string) (source.Driver, error) {
+	return nil, fmt.Errorf("merged source cannot be opened by url")
+}
+
+func (s *mergedSource) Close() error {
+	return s.sql.Close()
+}
+
+func (s *mergedSource) First() (uint, error) {
+	if len(s.versions) == 0 {
+		return 0, notExist("first")
+	}
+	return s.versions[0], nil
+}
+
+func (s *mergedSource) Prev(version uint) (uint, error) {
+	i, ok := s.index(version)
+	if !ok || i == 0 {
+		return 0, notExist(fmt.Sprintf("prev for version %d", version))
+	}
+	return s.versions[i-1], nil
+}
+
+func (s *mergedSource) Next(version uint) (uint, error) {
+	i, ok := s.index(version)
+	if !ok || i == len(s.versions)-1 {
+		return 0, notExist(fmt.Sprintf("next for version %d", version))
+	}
+	return s.versions[i+1], nil
+}
+
+func (s *mergedSource) index(version uint) (int, bool) {
+	i := sort.Search(len(s.versions), func(i int) bool { return s.versions[i] >= version })
+	return i, i < len(s.versions) && s.versions[i] == version
+}
+
+func (s *mergedSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	if m, ok := s.migrations[version]; ok {
+		return goBody(version, "up"), m.name, nil
+	}
+	return s.sql.ReadUp(version)
+}
+
+func (s *mergedSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	if m, ok := s.migrations[version]; ok {
+		if m.down == nil {
+			return nil, "", notExist(fmt.Sprintf("down for version %d", version))
+		}
+		return goBody(version, "down"), m.name, nil
+	}
+	return s.sql.ReadDown(version)
+}
+
+func notExist(op string) error {
+	return &fs.PathError{Op: op, Path: "migrations", Err: fs.ErrNotExist}
+}
+
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
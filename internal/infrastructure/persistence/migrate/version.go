@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Latest returns the highest version among the SQL files in files and the
+// registered Go migrations, without touching a database
+func Latest(files fs.FS) (uint, error) {
+	sqlSource, err := iofs.New(files, ".")
+	if err != nil {
+		return 0, fmt.Errorf("could not read migrations: %w", err)
+	}
+	src, err := newMergedSource(sqlSource, registeredGoMigrations())
+	if err != nil {
+		sqlSource.Close()
+		return 0, err
+	}
+	defer src.Close()
+
+	if len(src.versions) == 0 {
+		return 0, nil
+	}
+	return src.versions[len(src.versions)-1], nil
+}
+
+// AppliedVersion reads the version recorded by the migrator through db, so it
+// can be checked with the application's pool. ok is false when no migration was
+// applied yet.
+func AppliedVersion(ctx context.Context, db *sql.DB) (version uint, dirty bool, ok bool, err error) {
+	row := db.QueryRowContext(ctx, "SELECT version, dirty FROM `"+mysql.DefaultMigrationsTable+"` LIMIT 1")
+	if err := row.Scan(&version, &dirty); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, false, nil
+		}
+		return 0, false, false, err
+	}
+	return version, dirty, true, nil
+}
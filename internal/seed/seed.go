@@ -0,0 +1,176 @@
+// Package seed loads reproducible data sets for local development, demos, load
+// tests and integration tests. Everything is created through the usecases, so
+// passwords are hashed and audit entries are written like for real users.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/user"
+	userUsecase "go-clean-v3/internal/usecase/user"
+	"go-clean-v3/pkg/logger"
+	"runtime"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// UserFixture describes a user to create. Deleted users are moved to the trash
+// right after they are created.
+type UserFixture struct {
+	Name     string
+	Email    string
+	Password string
+	Role     string
+	Deleted  bool
+}
+
+// Set is a named group of fixtures
+type Set struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, s *Seeder) error
+}
+
+// Options tune the generated sets
+type Options struct {
+	// LoadTestUsers is the number of users the load-test set creates
+	LoadTestUsers int
+	// Concurrency is how many fixtures are created at once; bcrypt dominates the cost
+	Concurrency int
+}
+
+type Seeder struct {
+	users    *userUsecase.UserUsecase
+	userRepo user.UserRepositoryInterface
+	opts     Options
+}
+
+func NewSeeder(users *userUsecase.UserUsecase, userRepo user.UserRepositoryInterface, opts Options) *Seeder {
+	if opts.LoadTestUsers <= 0 {
+		opts.LoadTestUsers = 1000
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = runtime.NumCPU()
+	}
+
+	return &Seeder{
+		users:    users,
+		userRepo: userRepo,
+		opts:     opts,
+	}
+}
+
+// Sets returns the available sets ordered by name
+func Sets() []Set {
+	list := make([]Set, 0, len(sets))
+	for _, set := range sets {
+		list = append(list, set)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Run loads the named sets in order. Fixtures whose email already exists, in the
+// trash too, are skipped, so running a set twice is harmless.
+func (s *Seeder) Run(ctx context.Context, names ...string) error {
+	for _, name := range names {
+		set, ok := sets[name]
+		if !ok {
+			return fmt.Errorf("unknown seed set %q", name)
+		}
+
+		logger.Info("[Seeder-Run-1] Seeding", map[string]interface{}{"set": name})
+		if err := set.Run(ctx, s); err != nil {
+			return fmt.Errorf("seed set %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Users creates fixtures concurrently and returns how many were new
+func (s *Seeder) Users(ctx context.Context, fixtures []UserFixture) (int, error) {
+	created := make([]bool, len(fixtures))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.opts.Concurrency)
+	for i, fixture := range fixtures {
+		g.Go(func() error {
+			ok, err := s.user(ctx, fixture)
+			created[i] = ok
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, ok := range created {
+		if ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Seeder) user(ctx context.Context, fixture UserFixture) (bool, error) {
+	exists, err := s.exists(ctx, fixture.Email)
+	if err != nil || exists {
+		return false, err
+	}
+
+	created, err := s.users.Register(ctx, userUsecase.RegisterUserRequest{
+		Name:     fixture.Name,
+		Email:    fixture.Email,
+		Password: fixture.Password,
+	})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", fixture.Email, err)
+	}
+	resolved, err := s.users.Resolve(ctx, created.ID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", fixture.Email, err)
+	}
+	id := resolved.ID
+
+	if fixture.Role != "" && fixture.Role != user.RoleUser {
+		if err := s.users.ChangeRole(ctx, id, fixture.Role); err != nil {
+			return false, fmt.Errorf("%s: %w", fixture.Email, err)
+		}
+	}
+	if fixture.Deleted {
+		if err := s.users.DeleteAccount(ctx, id); err != nil {
+			return false, fmt.Errorf("%s: %w", fixture.Email, err)
+		}
+	}
+
+	return true, nil
+}
+
+func (s *Seeder) exists(ctx context.Context, email string) (bool, error) {
+	email = user.NormalizeEmail(email)
+	_, err := s.userRepo.GetByEmail(ctx, email)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, user.ErrUserNotFound) {
+		return false, err
+	}
+
+	spec := query.Spec{
+		Limit:   1,
+		Filters: []query.Filter{{Field: "email", Op: query.OpEq, Value: email}},
+	}
+	if err := user.DeletedListSchema.Normalize(&spec); err != nil {
+		return false, err
+	}
+	deleted, err := s.userRepo.ListDeleted(ctx, spec)
+	if err != nil {
+		return false, err
+	}
+	return deleted.Total > 0, nil
+}
@@ -0,0 +1,93 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/pkg/logger"
+	"math/rand"
+)
+
+const (
+	// DemoPassword is the password of every generated user
+	DemoPassword = "password123"
+
+	AdminEmail = "admin@example.com"
+	UserEmail  = "user@example.com"
+)
+
+var sets = map[string]Set{
+	"minimal": {
+		Name:        "minimal",
+		Description: "one admin and one regular user",
+		Run:         seedMinimal,
+	},
+	"demo": {
+		Name:        "demo",
+		Description: "minimal plus 25 named users, a few of them in the trash",
+		Run:         seedDemo,
+	},
+	"load-test": {
+		Name:        "load-test",
+		Description: "minimal plus Options.LoadTestUsers generated users",
+		Run:         seedLoadTest,
+	},
+}
+
+var (
+	firstNames = []string{"Ayu", "Budi", "Citra", "Dewi", "Eko", "Fajar", "Gita", "Hadi", "Indah", "Joko", "Kartika", "Lina", "Made", "Nina", "Oki"}
+	lastNames  = []string{"Santoso", "Wijaya", "Pratama", "Lestari", "Saputra", "Hidayat", "Kusuma", "Nugroho", "Putri", "Setiawan"}
+)
+
+func seedMinimal(ctx context.Context, s *Seeder) error {
+	_, err := s.Users(ctx, []UserFixture{
+		{Name: "Admin", Email: AdminEmail, Password: DemoPassword, Role: user.RoleAdmin},
+		{Name: "User", Email: UserEmail, Password: DemoPassword},
+	})
+	return err
+}
+
+func seedDemo(ctx context.Context, s *Seeder) error {
+	if err := seedMinimal(ctx, s); err != nil {
+		return err
+	}
+
+	// A fixed seed keeps the demo data identical between runs
+	fixtures := generateUsers(rand.New(rand.NewSource(1)), "demo", 25)
+	for i := range fixtures {
+		fixtures[i].Deleted = i%10 == 9
+	}
+
+	_, err := s.Users(ctx, fixtures)
+	return err
+}
+
+func seedLoadTest(ctx context.Context, s *Seeder) error {
+	if err := seedMinimal(ctx, s); err != nil {
+		return err
+	}
+
+	created, err := s.Users(ctx, generateUsers(rand.New(rand.NewSource(2)), "load", s.opts.LoadTestUsers))
+	if err != nil {
+		return err
+	}
+
+	logger.Info("[Seeder-seedLoadTest-1] Load test users created", map[string]interface{}{"created": created})
+	return nil
+}
+
+// generateUsers returns n users with names drawn from rnd and emails that are
+// unique per prefix
+func generateUsers(rnd *rand.Rand, prefix string, n int) []UserFixture {
+	fixtures := make([]UserFixture, n)
+	for i := range fixtures {
+		first := firstNames[rnd.Intn(len(firstNames))]
+		last := lastNames[rnd.Intn(len(lastNames))]
+		fixtures[i] = UserFixture{
+			Name:     first + " " + last,
+			Email:    fmt.Sprintf("%s.%d@example.com", prefix, i+1),
+			Password: DemoPassword,
+		}
+	}
+	return fixtures
+}
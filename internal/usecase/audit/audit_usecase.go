@@ -0,0 +1,24 @@
+package audit
+
+import (
+	"context"
+	"go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/pkg/tracing"
+)
+
+type AuditUsecase struct {
+	auditRepo audit.AuditRepositoryInterface
+}
+
+func NewAuditUsecase(auditRepo audit.AuditRepositoryInterface) *AuditUsecase {
+	return &AuditUsecase{auditRepo: auditRepo}
+}
+
+// List returns a page of audit entries for administrators
+func (a *AuditUsecase) List(ctx context.Context, spec query.Spec) (_ *query.Page[*audit.Entry], err error) {
+	ctx, span := tracing.Start(ctx, "AuditUsecase.List")
+	defer tracing.End(span, &err)
+
+	return a.auditRepo.List(ctx, spec)
+}
@@ -1,44 +1,90 @@
-package auth
-
-import (
-	"context"
-	"go-clean-v3/internal/domain/auth"
-	"go-clean-v3/internal/domain/user"
-	userReq "go-clean-v3/internal/usecase/user"
-
-	"golang.org/x/crypto/bcrypt"
-)
-
-type AuthUsecase struct {
-	userRepo user.UserRepositoryInterface
-	authService auth.AuthServiceInterface
-}
-
-func NewAuthUsecase(userRepo user.UserRepositoryInterface, authService auth.AuthServiceInterface) *AuthUsecase {
-	return &AuthUsecase{
-		userRepo:   userRepo,
-		authService: authService,
-	}
-}
-
-func (a *AuthUsecase) GetUserFromContext(ctx context.Context) (*user.User, error) {
-	return nil, nil
-}
-
-func (a *AuthUsecase) Login(ctx context.Context, req userReq.LoginUserRequest) (string, error) {
-	dbUser, err := a.userRepo.GetByEmail(req.Email)
-	if err != nil {
-		return "", err
-	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(req.Password)); err != nil {
-		return "", err
-	}
-
-	token, err := a.authService.GenerateToken(dbUser)
-	if err != nil {
-		return "", err
-	}
-
-	return token, nil
+package auth
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/auth"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/transaction"
+	"go-clean-v3/internal/domain/user"
+	userReq "go-clean-v3/internal/usecase/user"
+	"go-clean-v3/pkg/logger"
+	"go-clean-v3/pkg/metrics"
+	"go-clean-v3/pkg/tracing"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthUsecase struct {
+	userRepo user.UserRepositoryInterface
+	authService auth.AuthServiceInterface
+	auditLogger audit.AuditLogger
+}
+
+func NewAuthUsecase(userRepo user.UserRepositoryInterface, authService auth.AuthServiceInterface, auditLogger audit.AuditLogger) *AuthUsecase {
+	return &AuthUsecase{
+		userRepo:   userRepo,
+		authService: authService,
+		auditLogger: auditLogger,
+	}
+}
+
+func (a *AuthUsecase) GetUserFromContext(ctx context.Context) (*user.User, error) {
+	return nil, nil
+}
+
+func (a *AuthUsecase) Login(ctx context.Context, req userReq.LoginUserRequest) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.Login")
+	defer tracing.End(span, &err)
+
+	req.Email = user.NormalizeEmail(req.Email)
+	// The primary has the password hash, which cached users lack
+	dbUser, err := a.userRepo.GetByEmail(transaction.ForcePrimary(ctx), req.Email)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			userReq.RejectUnknownEmail(req.Password)
+			a.logFailedLogin(ctx, "")
+			return "", user.ErrInvalidCredentials
+		}
+		return "", err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(req.Password)); err != nil {
+		a.logFailedLogin(ctx, strconv.FormatInt(dbUser.ID, 10))
+		return "", user.ErrInvalidCredentials
+	}
+
+	token, err := a.authService.GenerateToken(dbUser)
+	if err != nil {
+		return "", domainErrors.ErrInternal.Wrap(err)
+	}
+
+	if err := a.auditLogger.Log(ctx, &audit.Entry{
+		ActorID:    &dbUser.ID,
+		Action:     audit.ActionLogin,
+		EntityType: audit.EntityUser,
+		EntityID:   strconv.FormatInt(dbUser.ID, 10),
+	}); err != nil {
+		return "", err
+	}
+
+	metrics.LoginSucceeded()
+	return token, nil
+}
+
+// logFailedLogin records and counts a rejected login attempt; it must not hide the original error.
+// The attempted email is left out, as audit logs are stored in plaintext and
+// served to admins, even for addresses that have no account.
+func (a *AuthUsecase) logFailedLogin(ctx context.Context, userID string) {
+	metrics.LoginFailed()
+	err := a.auditLogger.Log(ctx, &audit.Entry{
+		Action:     audit.ActionLoginFailed,
+		EntityType: audit.EntityUser,
+		EntityID:   userID,
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "[AuthUsecase-logFailedLogin-1] Audit failed", map[string]interface{}{"error": err.Error()})
+	}
 }
\ No newline at end of file
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/user"
+	userReq "go-clean-v3/internal/usecase/user"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// stubUsers finds only the users it holds
+type stubUsers struct {
+	user.UserRepositoryInterface
+	users map[string]*user.User
+}
+
+func (s stubUsers) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	if u, ok := s.users[email]; ok {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+// auditRecorder keeps every entry it is given
+type auditRecorder struct {
+	entries []*audit.Entry
+}
+
+func (r *auditRecorder) Log(ctx context.Context, entry *audit.Entry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestFailedLoginAuditHoldsNoEmail(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("Password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := stubUsers{users: map[string]*user.User{
+		"alice@example.com": {ID: 1, Email: "alice@example.com", Password: string(hash)},
+	}}
+	auditLog := &auditRecorder{}
+	usecase := NewAuthUsecase(users, nil, auditLog)
+
+	attempts := map[string]string{
+		"wrong password": "Alice@Example.com",
+		"unknown email":  "nobody@example.com",
+	}
+	for name, email := range attempts {
+		_, err := usecase.Login(context.Background(), userReq.LoginUserRequest{Email: email, Password: "wrong"})
+		if !errors.Is(err, user.ErrInvalidCredentials) {
+			t.Errorf("%s: err = %v, want ErrInvalidCredentials", name, err)
+		}
+	}
+
+	if len(auditLog.entries) != len(attempts) {
+		t.Fatalf("recorded %d audit entries, want %d", len(auditLog.entries), len(attempts))
+	}
+	for _, entry := range auditLog.entries {
+		if entry.Action != audit.ActionLoginFailed {
+			t.Errorf("action = %q, want %q", entry.Action, audit.ActionLoginFailed)
+		}
+		raw, _ := json.Marshal(entry)
+		if strings.Contains(strings.ToLower(string(raw)), "example.com") {
+			t.Errorf("audit entry %s holds the email", raw)
+		}
+	}
+	if auditLog.entries[0].EntityID != "1" && auditLog.entries[1].EntityID != "1" {
+		t.Error("the failed login of an existing user does not name the user")
+	}
+}
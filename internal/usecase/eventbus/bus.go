@@ -0,0 +1,288 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/internal/domain/transaction"
+	"go-clean-v3/pkg/logger"
+	"go-clean-v3/pkg/requestid"
+	"sync"
+	"time"
+)
+
+const (
+	retryInitialBackoff = 100 * time.Millisecond
+	retryMaxBackoff     = 10 * time.Second
+)
+
+var errWrongEvent = errors.New("event does not match the subscribed type")
+
+// Handler reacts to an event. A sync handler error aborts the change that raised
+// the event; an async one is retried.
+type Handler func(ctx context.Context, e event.Event) error
+
+// DeadLetter is an async delivery that failed on every attempt
+type DeadLetter struct {
+	Event     event.Event
+	Handler   string
+	Attempts  int
+	Err       error
+	RequestID string
+}
+
+type subscriber struct {
+	name string
+	fn   Handler
+}
+
+type job struct {
+	event     event.Event
+	sub       subscriber
+	requestID string
+}
+
+// Bus dispatches recorded events to in-process subscribers.
+//
+// Sync subscribers run inside Record, so within the caller's transaction. Async
+// subscribers run on a worker pool once the transaction has committed, with a
+// context that carries none of the caller's values but its request ID. Events are also passed on to
+// next, usually the outbox, so other systems still see them.
+type Bus struct {
+	next        event.Recorder
+	queue       chan job
+	maxAttempts int
+
+	mu     sync.RWMutex
+	sync   map[string][]subscriber
+	async  map[string][]subscriber
+	closed bool
+
+	onDeadLetter func(DeadLetter)
+	// senders counts the enqueue calls that may still send to queue, which is
+	// only closed once they are done
+	senders sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+func NewBus(next event.Recorder, workers int, queueSize int, maxAttempts int) (*Bus, error) {
+	switch {
+	case workers <= 0:
+		return nil, fmt.Errorf("event bus workers must be positive, got %d", workers)
+	case queueSize < 0:
+		return nil, fmt.Errorf("event bus queue size must not be negative, got %d", queueSize)
+	case maxAttempts <= 0:
+		return nil, fmt.Errorf("event bus max attempts must be positive, got %d", maxAttempts)
+	}
+
+	b := &Bus{
+		next:        next,
+		queue:       make(chan job, queueSize),
+		maxAttempts: maxAttempts,
+		sync:        map[string][]subscriber{},
+		async:       map[string][]subscriber{},
+		onDeadLetter: func(dl DeadLetter) {
+			logger.Error("[Bus-deadLetter-1] Event dead-lettered", map[string]interface{}{
+				"event_type":   dl.Event.EventName(),
+				"aggregate_id": dl.Event.AggregateID(),
+				"handler":      dl.Handler,
+				"attempts":     dl.Attempts,
+				"error":        dl.Err.Error(),
+				"request_id":   dl.RequestID,
+			})
+		},
+	}
+
+	for i := 0; i < workers; i++ {
+		b.workers.Add(1)
+		go b.work()
+	}
+	return b, nil
+}
+
+// Subscribe runs fn for every E inside the transaction that records it. E must be
+// a value type, as its zero value is asked for the event name.
+func Subscribe[E event.Event](b *Bus, name string, fn func(ctx context.Context, e E) error) {
+	b.add(b.sync, nameOf[E](), subscriber{name: name, fn: typed(fn)})
+}
+
+// SubscribeAsync runs fn for every E on the worker pool after the recording
+// transaction has committed. Failures are retried and then dead-lettered.
+func SubscribeAsync[E event.Event](b *Bus, name string, fn func(ctx context.Context, e E) error) {
+	b.add(b.async, nameOf[E](), subscriber{name: name, fn: typed(fn)})
+}
+
+func (b *Bus) add(subs map[string][]subscriber, eventName string, sub subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs[eventName] = append(subs[eventName], sub)
+}
+
+func nameOf[E event.Event]() string {
+	var zero E
+	return zero.EventName()
+}
+
+// typed adapts a handler of one event type to Handler
+func typed[E event.Event](fn func(ctx context.Context, e E) error) Handler {
+	return func(ctx context.Context, e event.Event) error {
+		typedEvent, ok := e.(E)
+		if !ok {
+			return fmt.Errorf("%w: got %T", errWrongEvent, e)
+		}
+		return fn(ctx, typedEvent)
+	}
+}
+
+// OnDeadLetter replaces the default dead-letter handler, which logs the failure
+func (b *Bus) OnDeadLetter(fn func(DeadLetter)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onDeadLetter = fn
+}
+
+// Record implements event.Recorder. It runs the sync subscribers, passes the
+// events on and queues the async deliveries for after the commit.
+func (b *Bus) Record(ctx context.Context, events ...event.Event) error {
+	requestID := requestid.FromContext(ctx)
+	b.mu.RLock()
+	var syncJobs, jobs []job
+	for _, e := range events {
+		for _, sub := range b.sync[e.EventName()] {
+			syncJobs = append(syncJobs, job{event: e, sub: sub})
+		}
+		for _, sub := range b.async[e.EventName()] {
+			jobs = append(jobs, job{event: e, sub: sub, requestID: requestID})
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, j := range syncJobs {
+		if err := call(ctx, j); err != nil {
+			return fmt.Errorf("%s: %w", j.sub.name, err)
+		}
+	}
+
+	if b.next != nil {
+		if err := b.next.Record(ctx, events...); err != nil {
+			return err
+		}
+	}
+
+	if len(jobs) > 0 {
+		transaction.AfterCommit(ctx, func() { b.enqueue(jobs) })
+	}
+	return nil
+}
+
+// enqueue hands jobs to the workers. When the queue is full, or once the bus is
+// shut down, they are delivered by the caller instead, so nothing committed is
+// dropped and no lock is held while waiting for room.
+func (b *Bus) enqueue(jobs []job) {
+	b.mu.RLock()
+	closed := b.closed
+	if !closed {
+		b.senders.Add(1)
+	}
+	b.mu.RUnlock()
+
+	if closed {
+		for _, j := range jobs {
+			b.deliver(j)
+		}
+		return
+	}
+	defer b.senders.Done()
+
+	for _, j := range jobs {
+		select {
+		case b.queue <- j:
+		default:
+			logger.Error("[Bus-enqueue-1] Event queue is full, delivering in the caller", map[string]interface{}{
+				"event_type": j.event.EventName(),
+				"handler":    j.sub.name,
+			})
+			b.deliver(j)
+		}
+	}
+}
+
+func (b *Bus) work() {
+	defer b.workers.Done()
+	for j := range b.queue {
+		b.deliver(j)
+	}
+}
+
+// deliver calls an async subscriber until it succeeds or runs out of attempts
+func (b *Bus) deliver(j job) {
+	ctx := context.Background()
+	if j.requestID != "" {
+		ctx = requestid.NewContext(ctx, j.requestID)
+	}
+	wait := retryInitialBackoff
+
+	var err error
+	for attempt := 1; attempt <= b.maxAttempts; attempt++ {
+		if err = call(ctx, j); err == nil {
+			return
+		}
+
+		logger.ErrorContext(ctx, "[Bus-deliver-1] Event handler failed", map[string]interface{}{
+			"event_type": j.event.EventName(),
+			"handler":    j.sub.name,
+			"attempt":    attempt,
+			"error":      err.Error(),
+		})
+		if attempt < b.maxAttempts {
+			time.Sleep(wait)
+			wait = min(wait*2, retryMaxBackoff)
+		}
+	}
+
+	b.mu.RLock()
+	onDeadLetter := b.onDeadLetter
+	b.mu.RUnlock()
+	onDeadLetter(DeadLetter{Event: j.event, Handler: j.sub.name, Attempts: b.maxAttempts, Err: err, RequestID: j.requestID})
+}
+
+// Shutdown stops accepting async deliveries and waits until the queued ones are
+// done, or ctx expires
+func (b *Bus) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	first := !b.closed
+	b.closed = true
+	b.mu.Unlock()
+
+	if first {
+		// enqueue calls that saw the bus open may still be sending
+		go func() {
+			b.senders.Wait()
+			close(b.queue)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		b.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event bus shutdown: %d deliveries still queued: %w", len(b.queue), ctx.Err())
+	}
+}
+
+// call runs a subscriber, turning a panic into an error
+func call(ctx context.Context, j job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.sub.fn(ctx, j.event)
+}
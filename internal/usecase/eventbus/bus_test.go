@@ -0,0 +1,263 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/internal/domain/transaction"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type pinged struct {
+	ID string
+}
+
+func (pinged) EventName() string     { return "test.pinged" }
+func (pinged) AggregateType() string { return "test" }
+func (e pinged) AggregateID() string { return e.ID }
+
+// recorder stands in for the outbox
+type recorder struct {
+	mu     sync.Mutex
+	events []event.Event
+}
+
+func (r *recorder) Record(ctx context.Context, events ...event.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, events...)
+	return nil
+}
+
+func newTestBus(t *testing.T, workers int, queueSize int, maxAttempts int) *Bus {
+	t.Helper()
+	b, err := NewBus(&recorder{}, workers, queueSize, maxAttempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Shutdown(context.Background()) })
+	return b
+}
+
+func TestNewBusRejectsInvalidSettings(t *testing.T) {
+	for name, args := range map[string][3]int{
+		"no workers":    {0, 10, 3},
+		"negative size": {1, -1, 3},
+		"no attempts":   {1, 10, 0},
+	} {
+		if _, err := NewBus(nil, args[0], args[1], args[2]); err == nil {
+			t.Errorf("%s: NewBus accepted %v", name, args)
+		}
+	}
+}
+
+func TestSyncSubscriberErrorAbortsRecord(t *testing.T) {
+	next := &recorder{}
+	b, err := NewBus(next, 1, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Shutdown(context.Background())
+
+	refused := errors.New("refused")
+	Subscribe(b, "refuse", func(ctx context.Context, e pinged) error { return refused })
+
+	if err := b.Record(context.Background(), pinged{ID: "1"}); !errors.Is(err, refused) {
+		t.Errorf("err = %v, want the subscriber error", err)
+	}
+	if len(next.events) != 0 {
+		t.Error("the event was passed on after a sync subscriber failed")
+	}
+}
+
+func TestAsyncDeliveryWaitsForCommit(t *testing.T) {
+	b := newTestBus(t, 1, 10, 3)
+	delivered := make(chan string, 1)
+	SubscribeAsync(b, "ping", func(ctx context.Context, e pinged) error {
+		delivered <- e.ID
+		return nil
+	})
+
+	ctx, commit := transaction.WithCommitHooks(context.Background())
+	if err := b.Record(ctx, pinged{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-delivered:
+		t.Fatal("delivered before the commit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	commit()
+	select {
+	case id := <-delivered:
+		if id != "1" {
+			t.Errorf("delivered %q, want 1", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not delivered after the commit")
+	}
+}
+
+func TestAsyncDeliveryRetries(t *testing.T) {
+	b := newTestBus(t, 1, 10, 3)
+	var calls atomic.Int32
+	done := make(chan struct{})
+	SubscribeAsync(b, "flaky", func(ctx context.Context, e pinged) error {
+		if calls.Add(1) < 3 {
+			return errors.New("not yet")
+		}
+		close(done)
+		return nil
+	})
+	b.OnDeadLetter(func(dl DeadLetter) { t.Errorf("dead-lettered after %d attempts", dl.Attempts) })
+
+	if err := b.Record(context.Background(), pinged{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler called %d times, want 3", calls.Load())
+	}
+}
+
+func TestAsyncDeliveryDeadLetters(t *testing.T) {
+	b := newTestBus(t, 1, 10, 2)
+	failure := errors.New("always fails")
+	SubscribeAsync(b, "broken", func(ctx context.Context, e pinged) error { return failure })
+	SubscribeAsync(b, "panics", func(ctx context.Context, e pinged) error { panic("boom") })
+
+	letters := make(chan DeadLetter, 2)
+	b.OnDeadLetter(func(dl DeadLetter) { letters <- dl })
+
+	if err := b.Record(context.Background(), pinged{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]DeadLetter{}
+	for len(got) < 2 {
+		select {
+		case dl := <-letters:
+			got[dl.Handler] = dl
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d dead letters, want 2", len(got))
+		}
+	}
+	if dl := got["broken"]; dl.Attempts != 2 || !errors.Is(dl.Err, failure) || dl.Event.AggregateID() != "1" {
+		t.Errorf("broken = %+v, want 2 attempts failing with the handler error", dl)
+	}
+	if dl := got["panics"]; dl.Err == nil {
+		t.Error("a panic was not turned into an error")
+	}
+}
+
+func TestShutdownDrainsQueue(t *testing.T) {
+	b, err := NewBus(nil, 1, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delivered atomic.Int32
+	SubscribeAsync(b, "slow", func(ctx context.Context, e pinged) error {
+		time.Sleep(time.Millisecond)
+		delivered.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 20; i++ {
+		if err := b.Record(context.Background(), pinged{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := delivered.Load(); n != 20 {
+		t.Errorf("delivered %d events before Shutdown returned, want 20", n)
+	}
+
+	// After the shutdown the caller delivers
+	if err := b.Record(context.Background(), pinged{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := delivered.Load(); n != 21 {
+		t.Errorf("delivered %d events after the shutdown, want 21", n)
+	}
+}
+
+func TestShutdownTimesOut(t *testing.T) {
+	b, err := NewBus(nil, 1, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	defer close(release)
+	SubscribeAsync(b, "stuck", func(ctx context.Context, e pinged) error {
+		<-release
+		return nil
+	})
+	if err := b.Record(context.Background(), pinged{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestFullQueueDoesNotBlockShutdown(t *testing.T) {
+	b, err := NewBus(nil, 1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	var delivered atomic.Int32
+	SubscribeAsync(b, "blocked", func(ctx context.Context, e pinged) error {
+		started <- struct{}{}
+		<-release
+		delivered.Add(1)
+		return nil
+	})
+
+	// The first event occupies the worker and the second the queue, the third
+	// finds the queue full and is delivered by the caller
+	if err := b.Record(context.Background(), pinged{}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := b.Record(context.Background(), pinged{}); err != nil {
+		t.Fatal(err)
+	}
+	recorded := make(chan struct{})
+	go func() {
+		b.Record(context.Background(), pinged{})
+		close(recorded)
+	}()
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- b.Shutdown(context.Background()) }()
+
+	close(release)
+	select {
+	case <-recorded:
+	case <-time.After(time.Second):
+		t.Fatal("Record blocked on the full queue")
+	}
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown deadlocked")
+	}
+	if n := delivered.Load(); n != 3 {
+		t.Errorf("delivered %d events, want 3", n)
+	}
+}
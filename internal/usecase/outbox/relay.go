@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/pkg/logger"
+	"go-clean-v3/pkg/requestid"
+	"go-clean-v3/pkg/tracing"
+	"time"
+)
+
+const (
+	retryInitialBackoff = time.Second
+	retryMaxBackoff     = time.Hour
+)
+
+// Config tunes a Relay; every value must be positive
+type Config struct {
+	// BatchSize is how many messages are claimed at once
+	BatchSize int
+	// MaxAttempts is how often a message is tried before it is dead-lettered
+	MaxAttempts int
+	// PollInterval is how often the outbox is checked for due messages
+	PollInterval time.Duration
+	// Lease is how long claimed messages are reserved for the relay. It should
+	// cover publishing a whole batch; what is left when it runs out is released.
+	Lease time.Duration
+}
+
+// Relay publishes the messages written to the outbox. Each message is retried with
+// exponential backoff and dead-lettered after MaxAttempts failures. Messages of one
+// aggregate are published in the order they were recorded.
+type Relay struct {
+	outboxRepo event.OutboxRepositoryInterface
+	publisher  event.Publisher
+	cfg        Config
+}
+
+func NewRelay(outboxRepo event.OutboxRepositoryInterface, publisher event.Publisher, cfg Config) (*Relay, error) {
+	switch {
+	case cfg.BatchSize <= 0:
+		return nil, fmt.Errorf("outbox batch size must be positive, got %d", cfg.BatchSize)
+	case cfg.MaxAttempts <= 0:
+		return nil, fmt.Errorf("outbox max attempts must be positive, got %d", cfg.MaxAttempts)
+	case cfg.PollInterval <= 0:
+		return nil, fmt.Errorf("outbox poll interval must be positive, got %s", cfg.PollInterval)
+	case cfg.Lease <= 0:
+		return nil, fmt.Errorf("outbox claim lease must be positive, got %s", cfg.Lease)
+	}
+
+	return &Relay{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		cfg:        cfg,
+	}, nil
+}
+
+// RelayOnce publishes one batch of due messages and returns how many were claimed.
+// The claim is committed before anything is published, and every message is
+// marked on its own, so a failure to mark one does not hold back the others.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	messages, err := r.outboxRepo.ClaimPending(ctx, r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for i, msg := range messages {
+		// Past the lease another relay may claim the message too
+		if time.Now().After(msg.ClaimedUntil) {
+			logger.ErrorContext(ctx, "[Relay-RelayOnce-1] Claim expired before the batch was published", map[string]interface{}{
+				"left": len(messages) - i,
+			})
+			break
+		}
+		if err := r.publish(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(messages), errors.Join(errs...)
+}
+
+// publish delivers msg and records the outcome; only a failure to record is returned.
+// The request the message was recorded in is carried on to the publisher.
+func (r *Relay) publish(ctx context.Context, msg *event.Message) error {
+	if msg.RequestID != "" {
+		ctx = requestid.NewContext(ctx, msg.RequestID)
+	}
+	ctx, span := tracing.Start(ctx, "Relay.publish")
+	defer span.End()
+
+	err := r.publisher.Publish(ctx, msg)
+	if err == nil {
+		return r.outboxRepo.MarkPublished(ctx, msg)
+	}
+
+	attempts := msg.Attempts + 1
+	dead := attempts >= r.cfg.MaxAttempts
+	fields := map[string]interface{}{
+		"id":         msg.ID,
+		"event_type": msg.EventType,
+		"attempts":   attempts,
+		"error":      err.Error(),
+	}
+	if dead {
+		logger.ErrorContext(ctx, "[Relay-publish-1] Message dead-lettered", fields)
+	} else {
+		logger.ErrorContext(ctx, "[Relay-publish-2] Publish failed, will retry", fields)
+	}
+
+	return r.outboxRepo.MarkFailed(ctx, msg, err, time.Now().Add(backoff(attempts)), dead)
+}
+
+// backoff doubles the wait after every failed attempt
+func backoff(attempts int) time.Duration {
+	wait := retryInitialBackoff
+	for i := 1; i < attempts && wait < retryMaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > retryMaxBackoff {
+		wait = retryMaxBackoff
+	}
+	return wait
+}
+
+// Run relays messages every PollInterval until ctx is cancelled. Full batches are
+// followed by the next one right away.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				claimed, err := r.RelayOnce(ctx)
+				if err != nil {
+					logger.Error("[Relay-Run-1] Relay failed", map[string]interface{}{"error": err.Error()})
+					break
+				}
+				if claimed < r.cfg.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
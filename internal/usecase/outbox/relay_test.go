@@ -0,0 +1,227 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/domain/event"
+	"sync"
+	"testing"
+	"time"
+)
+
+type failure struct {
+	id   int64
+	next time.Time
+	dead bool
+}
+
+// stubOutbox hands out the queued batches one per claim and records how every
+// message was settled
+type stubOutbox struct {
+	event.OutboxRepositoryInterface
+
+	mu        sync.Mutex
+	batches   [][]*event.Message
+	claims    int
+	published []int64
+	failed    []failure
+	// markErr is returned by the marks of the message with that ID
+	markErr map[int64]error
+}
+
+func (s *stubOutbox) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*event.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.claims++
+	if len(s.batches) == 0 {
+		return nil, nil
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	for _, msg := range batch {
+		if msg.ClaimedUntil.IsZero() {
+			msg.ClaimedUntil = time.Now().Add(lease)
+		}
+	}
+	return batch, nil
+}
+
+func (s *stubOutbox) MarkPublished(ctx context.Context, msg *event.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.published = append(s.published, msg.ID)
+	return s.markErr[msg.ID]
+}
+
+func (s *stubOutbox) MarkFailed(ctx context.Context, msg *event.Message, cause error, next time.Time, dead bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failed = append(s.failed, failure{id: msg.ID, next: next, dead: dead})
+	return s.markErr[msg.ID]
+}
+
+func (s *stubOutbox) claimCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.claims
+}
+
+// stubPublisher fails the messages whose ID is in fail
+type stubPublisher struct {
+	fail map[int64]bool
+}
+
+func (p stubPublisher) Publish(ctx context.Context, msg *event.Message) error {
+	if p.fail[msg.ID] {
+		return errors.New("receiver is down")
+	}
+	return nil
+}
+
+var testConfig = Config{BatchSize: 10, MaxAttempts: 3, PollInterval: time.Millisecond, Lease: time.Minute}
+
+func newTestRelay(t *testing.T, repo *stubOutbox, publisher event.Publisher, cfg Config) *Relay {
+	t.Helper()
+	relay, err := NewRelay(repo, publisher, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return relay
+}
+
+func TestNewRelayRejectsInvalidConfig(t *testing.T) {
+	tests := map[string]func(*Config){
+		"batch size":    func(c *Config) { c.BatchSize = 0 },
+		"max attempts":  func(c *Config) { c.MaxAttempts = 0 },
+		"poll interval": func(c *Config) { c.PollInterval = 0 },
+		"lease":         func(c *Config) { c.Lease = -time.Second },
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := testConfig
+			change(&cfg)
+			if _, err := NewRelay(&stubOutbox{}, stubPublisher{}, cfg); err == nil {
+				t.Error("NewRelay accepted the config")
+			}
+		})
+	}
+}
+
+func TestRelayOncePublishesAndRetries(t *testing.T) {
+	repo := &stubOutbox{batches: [][]*event.Message{{
+		{ID: 1},
+		{ID: 2, Attempts: 0},
+		{ID: 3, Attempts: 2},
+	}}}
+	relay := newTestRelay(t, repo, stubPublisher{fail: map[int64]bool{2: true, 3: true}}, testConfig)
+
+	start := time.Now()
+	claimed, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed != 3 {
+		t.Errorf("claimed = %d, want 3", claimed)
+	}
+
+	if len(repo.published) != 1 || repo.published[0] != 1 {
+		t.Errorf("published = %v, want [1]", repo.published)
+	}
+	if len(repo.failed) != 2 {
+		t.Fatalf("failed = %v, want messages 2 and 3", repo.failed)
+	}
+
+	retried := repo.failed[0]
+	if retried.id != 2 || retried.dead {
+		t.Errorf("message 2 = %+v, want a retry", retried)
+	}
+	if wait := retried.next.Sub(start); wait < retryInitialBackoff || wait > retryInitialBackoff+time.Second {
+		t.Errorf("message 2 is retried after %s, want about %s", wait, retryInitialBackoff)
+	}
+	// The third failure reaches MaxAttempts
+	if dead := repo.failed[1]; dead.id != 3 || !dead.dead {
+		t.Errorf("message 3 = %+v, want it dead-lettered", dead)
+	}
+}
+
+func TestRelayOnceKeepsGoingWhenMarkFails(t *testing.T) {
+	repo := &stubOutbox{
+		batches: [][]*event.Message{{{ID: 1}, {ID: 2}, {ID: 3}}},
+		markErr: map[int64]error{1: event.ErrClaimLost, 2: errors.New("connection reset")},
+	}
+	relay := newTestRelay(t, repo, stubPublisher{fail: map[int64]bool{2: true}}, testConfig)
+
+	_, err := relay.RelayOnce(context.Background())
+	if !errors.Is(err, event.ErrClaimLost) {
+		t.Errorf("err = %v, want it to include ErrClaimLost", err)
+	}
+	if len(repo.published) != 2 || len(repo.failed) != 1 {
+		t.Errorf("published %v and failed %v, want every message marked", repo.published, repo.failed)
+	}
+}
+
+func TestRelayOnceStopsAtExpiredClaim(t *testing.T) {
+	expired := time.Now().Add(-time.Second)
+	repo := &stubOutbox{batches: [][]*event.Message{{
+		{ID: 1},
+		{ID: 2, ClaimedUntil: expired},
+		{ID: 3, ClaimedUntil: expired},
+	}}}
+	relay := newTestRelay(t, repo, stubPublisher{}, testConfig)
+
+	if _, err := relay.RelayOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.published) != 1 || repo.published[0] != 1 {
+		t.Errorf("published = %v, want only the message still claimed", repo.published)
+	}
+}
+
+func TestRunDrainsFullBatches(t *testing.T) {
+	cfg := testConfig
+	cfg.BatchSize = 2
+	repo := &stubOutbox{batches: [][]*event.Message{
+		{{ID: 1}, {ID: 2}},
+		{{ID: 3}, {ID: 4}},
+		{{ID: 5}},
+	}}
+	relay := newTestRelay(t, repo, stubPublisher{}, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for repo.claimCount() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if len(repo.published) != 5 {
+		t.Errorf("published = %v, want all 5 messages", repo.published)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		5:  16 * time.Second,
+		13: time.Hour,
+		40: time.Hour,
+	}
+	for attempts, want := range tests {
+		if got := backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package trash
+
+import "time"
+
+const EntityUser = "user"
+
+type TrashItemResponse struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
@@ -0,0 +1,144 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/transaction"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/pkg/logger"
+	"go-clean-v3/pkg/tracing"
+	"strconv"
+	"time"
+)
+
+type TrashUsecase struct {
+	userRepo      user.UserRepositoryInterface
+	txManager     transaction.Manager
+	auditLogger   audit.AuditLogger
+	events        event.Recorder
+	retention     time.Duration
+	purgeInterval time.Duration
+}
+
+// NewTrashUsecase keeps deleted entities restorable for retention and purges the
+// expired ones every purgeInterval
+func NewTrashUsecase(userRepo user.UserRepositoryInterface, txManager transaction.Manager, auditLogger audit.AuditLogger, events event.Recorder, retention time.Duration, purgeInterval time.Duration) (*TrashUsecase, error) {
+	switch {
+	case retention <= 0:
+		return nil, fmt.Errorf("trash retention must be positive, got %s", retention)
+	case purgeInterval <= 0:
+		return nil, fmt.Errorf("trash purge interval must be positive, got %s", purgeInterval)
+	}
+
+	return &TrashUsecase{
+		userRepo:      userRepo,
+		txManager:     txManager,
+		auditLogger:   auditLogger,
+		events:        events,
+		retention:     retention,
+		purgeInterval: purgeInterval,
+	}, nil
+}
+
+// List returns soft-deleted entities together with the time they will be purged
+func (t *TrashUsecase) List(ctx context.Context, spec query.Spec) (_ *query.Page[TrashItemResponse], err error) {
+	ctx, span := tracing.Start(ctx, "TrashUsecase.List")
+	defer tracing.End(span, &err)
+
+	page, err := t.userRepo.ListDeleted(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.MapPage(page, func(u *user.User) TrashItemResponse {
+		return TrashItemResponse{
+			Type:      EntityUser,
+			ID:        u.PublicID,
+			Name:      u.Name,
+			DeletedAt: *u.DeletedAt,
+			PurgeAt:   u.DeletedAt.Add(t.retention),
+		}
+	}), nil
+}
+
+// RestoreUser takes the user known by publicID out of the trash
+func (t *TrashUsecase) RestoreUser(ctx context.Context, publicID string) (err error) {
+	ctx, span := tracing.Start(ctx, "TrashUsecase.RestoreUser")
+	defer tracing.End(span, &err)
+
+	page, err := t.userRepo.ListDeleted(ctx, query.Spec{
+		Filters: []query.Filter{{Field: "id", Op: query.OpEq, Value: publicID}},
+		Limit:   1,
+	})
+	if err != nil {
+		return err
+	}
+	if len(page.Items) == 0 {
+		return user.ErrUserNotFound
+	}
+	id := page.Items[0].ID
+
+	return t.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := t.userRepo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		err := t.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionUserRestore,
+			EntityType: audit.EntityUser,
+			EntityID:   strconv.FormatInt(id, 10),
+		})
+		if err != nil {
+			return err
+		}
+
+		return t.events.Record(ctx, user.Restored{UserID: publicID})
+	})
+}
+
+// PurgeExpired permanently removes entities that have been in the trash longer than the retention period
+func (t *TrashUsecase) PurgeExpired(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "TrashUsecase.PurgeExpired")
+	defer tracing.End(span, &err)
+
+	var purged int64
+	err = t.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = t.userRepo.PurgeDeletedBefore(ctx, time.Now().Add(-t.retention))
+		if err != nil || purged == 0 {
+			return err
+		}
+
+		return t.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionTrashPurge,
+			EntityType: audit.EntityUser,
+			After:      map[string]interface{}{"purged": purged},
+		})
+	})
+	return purged, err
+}
+
+// RunPurger calls PurgeExpired every purge interval until ctx is cancelled
+func (t *TrashUsecase) RunPurger(ctx context.Context) {
+	ticker := time.NewTicker(t.purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := t.PurgeExpired(ctx)
+			if err != nil {
+				logger.Error("[TrashUsecase-RunPurger-1] Purge failed", map[string]interface{}{"error": err.Error()})
+				continue
+			}
+			if purged > 0 {
+				logger.Info("Purged expired trash", map[string]interface{}{"users": purged})
+			}
+		}
+	}
+}
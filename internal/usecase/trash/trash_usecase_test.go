@@ -0,0 +1,19 @@
+package trash
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTrashUsecaseRejectsInvalidSettings(t *testing.T) {
+	for name, args := range map[string][2]time.Duration{
+		"no retention":            {0, time.Hour},
+		"negative retention":      {-time.Hour, time.Hour},
+		"no purge interval":       {time.Hour, 0},
+		"negative purge interval": {time.Hour, -time.Minute},
+	} {
+		if _, err := NewTrashUsecase(nil, nil, nil, nil, args[0], args[1]); err == nil {
+			t.Errorf("%s: NewTrashUsecase accepted %v", name, args)
+		}
+	}
+}
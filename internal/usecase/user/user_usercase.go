@@ -1,85 +1,352 @@
-package user
-
-import (
-	"context"
-	"go-clean-v3/internal/domain/auth"
-	"go-clean-v3/internal/domain/user"
-
-	"golang.org/x/crypto/bcrypt"
-)
-
-type UserUsecase struct {
-	userRepo    user.UserRepositoryInterface
-	authService auth.AuthServiceInterface
-}
-
-func NewUserUsecase(userRepo user.UserRepositoryInterface, authService auth.AuthServiceInterface) *UserUsecase {
-	return &UserUsecase{
-		userRepo:    userRepo,
-		authService: authService,
-	}
-}
-
-func (u *UserUsecase) Register(ctx context.Context, req RegisterUserRequest) (*UserResponse, error) {
-	if _, err := u.userRepo.GetByEmail(req.Email); err == nil {
-		return nil, err
-	}
-
-	// hash password
-	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create user domain model
-	newUser := &user.User{
-		Name: req.Name,
-		Email: req.Email,
-		Password: string(hashPassword),
-	}
-
-	// save to repository
-	if err := u.userRepo.Create(newUser); err != nil {
-		return nil, err
-	}
-
-	// Return Response DTO
-	return &UserResponse{
-		ID: newUser.ID,
-		Name: newUser.Name,
-		Email: newUser.Email,
-	}, nil
-}
-
-func (u *UserUsecase) Login(ctx context.Context, req LoginUserRequest) (string, error) {
-	existUser, err := u.userRepo.GetByEmail(req.Email)
-	if err != nil {
-		return "", err
-	}
-
-	// Compoare Password
-	if err := bcrypt.CompareHashAndPassword([]byte(req.Password), []byte(existUser.Password)); err != nil {
-		return "", user.ErrUserNotFound
-	}
-
-	// Generate JWT token
-	token, err := u.authService.GenerateToken(existUser)
-	if err != nil {
-		return "", err
-	}
-
-	return token, nil
-}
-
-func (u *UserUsecase) GetProfile(ctx context.Context, userID int64) (*UserResponse, error) {
-	userData, err := u.userRepo.GetByID(userID)
-	if err != nil {
-		return nil, err
-	}
-
-	return &UserResponse{
-		ID: userData.ID,
-		Name: userData.Name,
-		Email: userData.Email,
-	}, nil
-}
\ No newline at end of file
+package user
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/auth"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/transaction"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/pkg/tracing"
+	"strconv"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash has the cost of real password hashes. Logins for unknown emails are
+// checked against it, so they take as long to fail as wrong passwords.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// RejectUnknownEmail spends the time of a password check on a login whose email
+// has no account, so response times do not tell who has one
+func RejectUnknownEmail(password string) {
+	_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+}
+
+type UserUsecase struct {
+	userRepo    user.UserRepositoryInterface
+	authService auth.AuthServiceInterface
+	txManager   transaction.Manager
+	auditLogger audit.AuditLogger
+	events      event.Recorder
+}
+
+func NewUserUsecase(userRepo user.UserRepositoryInterface, authService auth.AuthServiceInterface, txManager transaction.Manager, auditLogger audit.AuditLogger, events event.Recorder) *UserUsecase {
+	return &UserUsecase{
+		userRepo:    userRepo,
+		authService: authService,
+		txManager:   txManager,
+		auditLogger: auditLogger,
+		events:      events,
+	}
+}
+
+// auditFields is the part of a user recorded in audit diffs. The email is left
+// out, as audit logs are stored in plaintext.
+func auditFields(u *user.User) map[string]interface{} {
+	return map[string]interface{}{
+		"name": u.Name,
+		"role": u.Role,
+	}
+}
+
+func (u *UserUsecase) Register(ctx context.Context, req RegisterUserRequest) (_ *UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Register")
+	defer tracing.End(span, &err)
+
+	req.Email = user.NormalizeEmail(req.Email)
+	// Fails early in the common case; a concurrent registration is caught by Create
+	if _, err := u.userRepo.GetByEmail(ctx, req.Email); err == nil {
+		return nil, user.ErrEmailExists
+	} else if !errors.Is(err, user.ErrUserNotFound) {
+		return nil, err
+	}
+
+	// hash password
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, domainErrors.ErrInternal.Wrap(err)
+	}
+
+	// Create user domain model
+	newUser := &user.User{
+		Name: req.Name,
+		Email: req.Email,
+		Password: string(hashPassword),
+		Role: user.RoleUser,
+	}
+
+	// save to repository together with its audit entry
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Create(ctx, newUser); err != nil {
+			return err
+		}
+
+		err := u.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionRegister,
+			EntityType: audit.EntityUser,
+			EntityID:   strconv.FormatInt(newUser.ID, 10),
+			After:      auditFields(newUser),
+		})
+		if err != nil {
+			return err
+		}
+
+		return u.events.Record(ctx, user.Registered{UserID: newUser.PublicID, Name: newUser.Name})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Return Response DTO
+	return &UserResponse{
+		ID: newUser.PublicID,
+		Name: newUser.Name,
+		Email: newUser.Email,
+	}, nil
+}
+
+func (u *UserUsecase) Login(ctx context.Context, req LoginUserRequest) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Login")
+	defer tracing.End(span, &err)
+
+	// The primary has the password hash, which cached users lack
+	existUser, err := u.userRepo.GetByEmail(transaction.ForcePrimary(ctx), user.NormalizeEmail(req.Email))
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			RejectUnknownEmail(req.Password)
+			return "", user.ErrInvalidCredentials
+		}
+		return "", err
+	}
+
+	// Compare Password
+	if err := bcrypt.CompareHashAndPassword([]byte(existUser.Password), []byte(req.Password)); err != nil {
+		return "", user.ErrInvalidCredentials
+	}
+
+	// Generate JWT token
+	token, err := u.authService.GenerateToken(existUser)
+	if err != nil {
+		return "", domainErrors.ErrInternal.Wrap(err)
+	}
+
+	return token, nil
+}
+
+func (u *UserUsecase) GetProfile(ctx context.Context, userID int64) (_ *UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.GetProfile")
+	defer tracing.End(span, &err)
+
+	userData, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserResponse{
+		ID: userData.PublicID,
+		Name: userData.Name,
+		Email: userData.Email,
+		Version: userData.Version,
+	}, nil
+}
+
+// UpdateProfile changes the user's profile if it is still at expectedVersion,
+// otherwise errors.ErrPreconditionFailed is returned
+func (u *UserUsecase) UpdateProfile(ctx context.Context, userID int64, expectedVersion int64, req UpdateProfileRequest) (_ *UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.UpdateProfile")
+	defer tracing.End(span, &err)
+
+	userData, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := auditFields(userData)
+	if req.Name != nil {
+		userData.Name = *req.Name
+	}
+	if req.Email != nil {
+		userData.Email = user.NormalizeEmail(*req.Email)
+	}
+	userData.Version = expectedVersion
+
+	err = u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Update(ctx, userData); err != nil {
+			if errors.Is(err, domainErrors.ErrConflict) {
+				return domainErrors.ErrPreconditionFailed.Wrap(err)
+			}
+			return err
+		}
+
+		changedBefore, changedAfter := audit.Diff(before, auditFields(userData))
+		err := u.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionProfileUpdate,
+			EntityType: audit.EntityUser,
+			EntityID:   strconv.FormatInt(userData.ID, 10),
+			Before:     changedBefore,
+			After:      changedAfter,
+		})
+		if err != nil {
+			return err
+		}
+
+		return u.events.Record(ctx, user.ProfileUpdated{UserID: userData.PublicID, Name: userData.Name})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserResponse{
+		ID: userData.PublicID,
+		Name: userData.Name,
+		Email: userData.Email,
+		Version: userData.Version,
+	}, nil
+}
+
+// DeleteAccount moves the user's account to the trash; it can be restored until the retention period expires
+func (u *UserUsecase) DeleteAccount(ctx context.Context, userID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.DeleteAccount")
+	defer tracing.End(span, &err)
+
+	userData, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Delete(ctx, userID); err != nil {
+			return err
+		}
+
+		err := u.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionAccountDelete,
+			EntityType: audit.EntityUser,
+			EntityID:   strconv.FormatInt(userID, 10),
+		})
+		if err != nil {
+			return err
+		}
+
+		return u.events.Record(ctx, user.Deleted{UserID: userData.PublicID})
+	})
+}
+
+// ChangePassword replaces the user's password after checking the current one
+func (u *UserUsecase) ChangePassword(ctx context.Context, userID int64, req ChangePasswordRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.ChangePassword")
+	defer tracing.End(span, &err)
+
+	userData, err := u.userRepo.GetByID(transaction.ForcePrimary(ctx), userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(req.CurrentPassword)); err != nil {
+		return user.ErrInvalidPassword.WithDetails(domainErrors.FieldError{
+			Field:   "current_password",
+			Rule:    "current_password",
+			Message: "current password is incorrect",
+		})
+	}
+
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return domainErrors.ErrInternal.Wrap(err)
+	}
+	userData.Password = string(hashPassword)
+
+	return u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Update(ctx, userData); err != nil {
+			return err
+		}
+
+		// Never record password hashes, the action itself is the change
+		err := u.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionPasswordChange,
+			EntityType: audit.EntityUser,
+			EntityID:   strconv.FormatInt(userData.ID, 10),
+		})
+		if err != nil {
+			return err
+		}
+
+		return u.events.Record(ctx, user.PasswordChanged{UserID: userData.PublicID})
+	})
+}
+
+// ChangeRole grants role to the user, role must be user.RoleUser or user.RoleAdmin
+func (u *UserUsecase) ChangeRole(ctx context.Context, userID int64, role string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.ChangeRole")
+	defer tracing.End(span, &err)
+
+	if role != user.RoleUser && role != user.RoleAdmin {
+		return user.ErrInvalidRole.WithDetails(domainErrors.FieldError{
+			Field:   "role",
+			Rule:    "oneof",
+			Message: "role must be " + user.RoleUser + " or " + user.RoleAdmin,
+		})
+	}
+
+	userData, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if userData.Role == role {
+		return nil
+	}
+
+	before := auditFields(userData)
+	userData.Role = role
+
+	return u.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Update(ctx, userData); err != nil {
+			return err
+		}
+
+		changedBefore, changedAfter := audit.Diff(before, auditFields(userData))
+		return u.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionRoleChange,
+			EntityType: audit.EntityUser,
+			EntityID:   strconv.FormatInt(userData.ID, 10),
+			Before:     changedBefore,
+			After:      changedAfter,
+		})
+	})
+}
+
+// Resolve returns the user known by publicID. Users that deleted their
+// account are not found.
+func (u *UserUsecase) Resolve(ctx context.Context, publicID string) (_ *user.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.Resolve")
+	defer tracing.End(span, &err)
+
+	return u.userRepo.GetByPublicID(ctx, publicID)
+}
+
+// ListUsers returns a page of users for administrators
+func (u *UserUsecase) ListUsers(ctx context.Context, spec query.Spec) (_ *query.Page[UserResponse], err error) {
+	ctx, span := tracing.Start(ctx, "UserUsecase.ListUsers")
+	defer tracing.End(span, &err)
+
+	page, err := u.userRepo.List(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.MapPage(page, func(userData *user.User) UserResponse {
+		return UserResponse{
+			ID: userData.PublicID,
+			Name: userData.Name,
+			Email: userData.Email,
+		}
+	}), nil
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/domain/user"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// recordSpans installs a tracer provider that keeps every span for the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+// stubUsers finds only the users it holds
+type stubUsers struct {
+	user.UserRepositoryInterface
+	users map[int64]*user.User
+}
+
+func (s stubUsers) GetByID(ctx context.Context, id int64) (*user.User, error) {
+	if u, ok := s.users[id]; ok {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func TestUsecaseSpansRecordErrors(t *testing.T) {
+	recorder := recordSpans(t)
+	usecase := NewUserUsecase(stubUsers{users: map[int64]*user.User{1: {ID: 1, Name: "Alice"}}}, nil, nil, nil, nil)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	if _, err := usecase.GetProfile(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := usecase.GetProfile(ctx, 2); !errors.Is(err, user.ErrUserNotFound) {
+		t.Fatalf("err = %v, want ErrUserNotFound", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans, want 3", len(spans))
+	}
+	found, missing := spans[0], spans[1]
+	for _, span := range []sdktrace.ReadOnlySpan{found, missing} {
+		if span.Name() != "UserUsecase.GetProfile" || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("span %q is not a child of the request span", span.Name())
+		}
+	}
+
+	if found.Status().Code != codes.Unset || len(found.Events()) != 0 {
+		t.Errorf("successful span has status %v and events %v", found.Status(), found.Events())
+	}
+	if missing.Status().Code != codes.Error || missing.Status().Description != user.ErrUserNotFound.Error() {
+		t.Errorf("failed span has status %v, want the error", missing.Status())
+	}
+	if events := missing.Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("failed span has events %v, want the recorded error", events)
+	}
+}
+
+func TestDummyHashMatchesPasswordCost(t *testing.T) {
+	// Register hashes with bcrypt.DefaultCost; a cheaper dummy would make unknown
+	// emails fail faster than wrong passwords
+	cost, err := bcrypt.Cost(dummyHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Errorf("dummy hash cost = %d, want %d", cost, bcrypt.DefaultCost)
+	}
+}
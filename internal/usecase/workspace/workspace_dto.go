@@ -0,0 +1,28 @@
+package workspace
+
+import "time"
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type AddMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"omitempty,oneof=owner member"`
+}
+
+type WorkspaceResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MemberResponse struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceTokenResponse struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,209 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/auth"
+	domainErrors "go-clean-v3/internal/domain/errors"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/transaction"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/domain/workspace"
+	"go-clean-v3/pkg/tracing"
+	"strconv"
+)
+
+type WorkspaceUsecase struct {
+	workspaceRepo workspace.WorkspaceRepositoryInterface
+	userRepo      user.UserRepositoryInterface
+	authService   auth.AuthServiceInterface
+	txManager     transaction.Manager
+	auditLogger   audit.AuditLogger
+}
+
+func NewWorkspaceUsecase(workspaceRepo workspace.WorkspaceRepositoryInterface, userRepo user.UserRepositoryInterface, authService auth.AuthServiceInterface, txManager transaction.Manager, auditLogger audit.AuditLogger) *WorkspaceUsecase {
+	return &WorkspaceUsecase{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		authService:   authService,
+		txManager:     txManager,
+		auditLogger:   auditLogger,
+	}
+}
+
+func toWorkspaceResponse(w *workspace.Workspace) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:        w.PublicID,
+		Name:      w.Name,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+// Create makes a new workspace owned by userID
+func (w *WorkspaceUsecase) Create(ctx context.Context, userID int64, req CreateWorkspaceRequest) (_ *WorkspaceResponse, err error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceUsecase.Create")
+	defer tracing.End(span, &err)
+
+	ws := &workspace.Workspace{Name: req.Name}
+
+	err = w.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := w.workspaceRepo.Create(ctx, ws); err != nil {
+			return err
+		}
+
+		ctx = workspace.WithTenant(ctx, ws.ID)
+		if err := w.workspaceRepo.AddMember(ctx, &workspace.Membership{UserID: userID, Role: workspace.RoleOwner}); err != nil {
+			return err
+		}
+
+		return w.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionWorkspaceCreate,
+			EntityType: audit.EntityWorkspace,
+			EntityID:   strconv.FormatInt(ws.ID, 10),
+			After:      map[string]interface{}{"name": ws.Name},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toWorkspaceResponse(ws)
+	return &resp, nil
+}
+
+// ListMine returns the workspaces userID belongs to
+func (w *WorkspaceUsecase) ListMine(ctx context.Context, userID int64) (_ []WorkspaceResponse, err error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceUsecase.ListMine")
+	defer tracing.End(span, &err)
+
+	list, err := w.workspaceRepo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]WorkspaceResponse, len(list))
+	for i, ws := range list {
+		resp[i] = toWorkspaceResponse(ws)
+	}
+	return resp, nil
+}
+
+// Authorize returns the membership of userID in the workspace known by
+// workspaceID, or errors.ErrForbidden when the user is not a member. Unknown
+// workspaces are forbidden too, so their IDs cannot be probed.
+func (w *WorkspaceUsecase) Authorize(ctx context.Context, userID int64, workspaceID string) (_ *workspace.Membership, err error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceUsecase.Authorize")
+	defer tracing.End(span, &err)
+
+	ws, err := w.workspaceRepo.GetByPublicID(ctx, workspaceID)
+	if errors.Is(err, workspace.ErrWorkspaceNotFound) {
+		return nil, domainErrors.ErrForbidden
+	} else if err != nil {
+		return nil, err
+	}
+
+	membership, err := w.workspaceRepo.GetMember(workspace.WithTenant(ctx, ws.ID), userID)
+	if errors.Is(err, workspace.ErrMemberNotFound) {
+		return nil, domainErrors.ErrForbidden
+	}
+	return membership, err
+}
+
+// Token issues a token with the workspace known by workspaceID as the default workspace
+func (w *WorkspaceUsecase) Token(ctx context.Context, userID int64, workspaceID string) (_ *WorkspaceTokenResponse, err error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceUsecase.Token")
+	defer tracing.End(span, &err)
+
+	if _, err := w.Authorize(ctx, userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	userData, err := w.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := w.authService.GenerateWorkspaceToken(userData, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkspaceTokenResponse{Token: token}, nil
+}
+
+// ListMembers returns the members of the workspace in ctx
+func (w *WorkspaceUsecase) ListMembers(ctx context.Context, spec query.Spec) (_ *query.Page[MemberResponse], err error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceUsecase.ListMembers")
+	defer tracing.End(span, &err)
+
+	page, err := w.workspaceRepo.ListMembers(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return query.MapPage(page, func(m *workspace.Membership) MemberResponse {
+		return MemberResponse{
+			UserID:    m.UserPublicID,
+			Role:      m.Role,
+			CreatedAt: m.CreatedAt,
+		}
+	}), nil
+}
+
+// AddMember adds the user with req.Email to the workspace in ctx. Only owners may add members.
+func (w *WorkspaceUsecase) AddMember(ctx context.Context, actorID int64, req AddMemberRequest) (_ *MemberResponse, err error) {
+	ctx, span := tracing.Start(ctx, "WorkspaceUsecase.AddMember")
+	defer tracing.End(span, &err)
+
+	role := req.Role
+	if role == "" {
+		role = workspace.RoleMember
+	}
+	if role != workspace.RoleOwner && role != workspace.RoleMember {
+		return nil, workspace.ErrInvalidRole
+	}
+
+	actor, err := w.workspaceRepo.GetMember(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, workspace.ErrMemberNotFound) {
+			return nil, domainErrors.ErrForbidden
+		}
+		return nil, err
+	}
+	if actor.Role != workspace.RoleOwner {
+		return nil, domainErrors.ErrForbidden
+	}
+
+	target, err := w.userRepo.GetByEmail(ctx, user.NormalizeEmail(req.Email))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.workspaceRepo.GetMember(ctx, target.ID); err == nil {
+		return nil, workspace.ErrAlreadyMember
+	} else if !errors.Is(err, workspace.ErrMemberNotFound) {
+		return nil, err
+	}
+
+	membership := &workspace.Membership{UserID: target.ID, Role: role}
+	err = w.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := w.workspaceRepo.AddMember(ctx, membership); err != nil {
+			return err
+		}
+
+		return w.auditLogger.Log(ctx, &audit.Entry{
+			Action:     audit.ActionMemberAdd,
+			EntityType: audit.EntityWorkspace,
+			EntityID:   strconv.FormatInt(membership.WorkspaceID, 10),
+			After:      map[string]interface{}{"user_id": target.ID, "role": role},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MemberResponse{
+		UserID:    target.PublicID,
+		Role:      membership.Role,
+		CreatedAt: membership.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL;
+
+CREATE INDEX idx_users_deleted_at ON users(deleted_at);
\ No newline at end of file
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"go-clean-v3/internal/infrastructure/persistence/migrate"
+	"go-clean-v3/pkg/logger"
+)
+
+func init() {
+	migrate.Register(6, "normalize_user_emails", normalizeUserEmails, nil)
+}
+
+// normalizeUserEmails lower-cases and trims the emails stored before they were
+// normalized on write. It cannot be reversed, the original casing is gone.
+//
+// Users whose emails only differ in case or whitespace would collide on the
+// unique index. Of each such group only one user is normalized: the one already
+// in normal form, else the oldest user that is not deleted. The others are left
+// as they are and logged, to be merged by hand.
+func normalizeUserEmails(ctx context.Context, tx *sql.Tx) error {
+	skip, err := duplicateEmailUsers(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return migrate.EachBatch(ctx, tx, "users", 500, func(ids []int64) error {
+		kept := ids[:0:0]
+		for _, id := range ids {
+			if !skip[id] {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+
+		in, args := migrate.InArgs(kept)
+		_, err := tx.ExecContext(ctx,
+			"UPDATE users SET email = LOWER(TRIM(email)) WHERE BINARY email <> LOWER(TRIM(email)) AND id IN ("+in+")",
+			args...)
+		return err
+	})
+}
+
+// duplicateEmailUsers returns the users that must keep their email because
+// another user gets its normalized form
+func duplicateEmailUsers(ctx context.Context, tx *sql.Tx) (map[int64]bool, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, LOWER(TRIM(email)) FROM users"+
+			" WHERE LOWER(TRIM(email)) IN (SELECT LOWER(TRIM(email)) FROM users GROUP BY LOWER(TRIM(email)) HAVING COUNT(*) > 1)"+
+			" ORDER BY LOWER(TRIM(email)), BINARY email = LOWER(TRIM(email)) DESC, deleted_at IS NOT NULL, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skip := map[int64]bool{}
+	seen := map[string]bool{}
+	var skipped []int64
+	for rows.Next() {
+		var id int64
+		var normalized string
+		if err := rows.Scan(&id, &normalized); err != nil {
+			return nil, err
+		}
+		// Rows are ordered so the first of every group is the one to normalize
+		if !seen[normalized] {
+			seen[normalized] = true
+			continue
+		}
+		skip[id] = true
+		skipped = append(skipped, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(skipped) > 0 {
+		logger.Error("[Migrations-normalizeUserEmails-1] Users share an email once normalized, left unchanged", map[string]interface{}{
+			"user_ids": skipped,
+		})
+	}
+	return skip, nil
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS outbox_messages;
\ No newline at end of file
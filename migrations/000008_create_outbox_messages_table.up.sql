@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    idempotency_key VARCHAR(64) NOT NULL,
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(128) NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(1024) NOT NULL DEFAULT '',
+    next_attempt_at DATETIME(3) NOT NULL,
+    published_at DATETIME(3) NULL DEFAULT NULL,
+    dead_at DATETIME(3) NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_outbox_messages_idempotency_key ON outbox_messages(idempotency_key);
+CREATE INDEX idx_outbox_messages_aggregate ON outbox_messages(aggregate_type, aggregate_id, id);
+CREATE INDEX idx_outbox_messages_pending ON outbox_messages(published_at, dead_at, next_attempt_at);
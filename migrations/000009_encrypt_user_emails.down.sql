@@ -0,0 +1,8 @@
+-- Fails while emails are encrypted, as they no longer fit VARCHAR(100)
+DROP INDEX idx_users_email_index ON users;
+
+ALTER TABLE users
+    DROP COLUMN email_index,
+    MODIFY COLUMN email VARCHAR(100) NOT NULL;
+
+CREATE UNIQUE INDEX idx_users_email ON users(email);
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE users
+    MODIFY COLUMN email VARCHAR(512) NOT NULL,
+    ADD COLUMN email_index CHAR(64) NULL DEFAULT NULL AFTER email;
+
+DROP INDEX idx_users_email ON users;
+
+CREATE UNIQUE INDEX idx_users_email_index ON users(email_index);
\ No newline at end of file
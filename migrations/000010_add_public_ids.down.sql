@@ -0,0 +1,3 @@
+ALTER TABLE workspaces DROP COLUMN public_id;
+
+ALTER TABLE users DROP COLUMN public_id;
\ No newline at end of file
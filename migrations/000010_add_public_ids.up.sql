@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN public_id CHAR(36) NULL DEFAULT NULL AFTER id;
+
+ALTER TABLE workspaces
+    ADD COLUMN public_id CHAR(36) NULL DEFAULT NULL AFTER id;
\ No newline at end of file
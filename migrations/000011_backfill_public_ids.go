@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"go-clean-v3/internal/infrastructure/persistence/migrate"
+	"go-clean-v3/pkg/publicid"
+	"strings"
+	"time"
+)
+
+func init() {
+	migrate.Register(11, "backfill_public_ids", backfillPublicIDs, func(ctx context.Context, tx *sql.Tx) error {
+		// Nothing to undo, the column is dropped by the down of version 10
+		return nil
+	})
+}
+
+// backfillPublicIDs gives existing users and workspaces a public ID. The IDs
+// carry the row's created_at, so they sort like rows created afterwards.
+func backfillPublicIDs(ctx context.Context, tx *sql.Tx) error {
+	for _, table := range []string{"users", "workspaces"} {
+		err := migrate.EachBatch(ctx, tx, table, 500, func(ids []int64) error {
+			return backfillBatch(ctx, tx, table, ids)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func backfillBatch(ctx context.Context, tx *sql.Tx, table string, ids []int64) error {
+	in, args := migrate.InArgs(ids)
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, CAST(UNIX_TIMESTAMP(COALESCE(created_at, NOW())) * 1000 AS SIGNED) FROM "+table+
+			" WHERE public_id IS NULL AND id IN ("+in+")",
+		args...)
+	if err != nil {
+		return err
+	}
+
+	var cases []string
+	var updateArgs []interface{}
+	for rows.Next() {
+		var id, createdMs int64
+		if err := rows.Scan(&id, &createdMs); err != nil {
+			rows.Close()
+			return err
+		}
+		cases = append(cases, "WHEN ? THEN ?")
+		updateArgs = append(updateArgs, id, publicid.At(time.UnixMilli(createdMs)))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(cases) == 0 {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE "+table+" SET public_id = CASE id "+strings.Join(cases, " ")+" END"+
+			" WHERE public_id IS NULL AND id IN ("+in+")",
+		append(updateArgs, args...)...)
+	return err
+}
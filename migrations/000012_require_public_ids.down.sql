@@ -0,0 +1,9 @@
+DROP INDEX idx_workspaces_public_id ON workspaces;
+
+ALTER TABLE workspaces
+    MODIFY COLUMN public_id CHAR(36) NULL DEFAULT NULL;
+
+DROP INDEX idx_users_public_id ON users;
+
+ALTER TABLE users
+    MODIFY COLUMN public_id CHAR(36) NULL DEFAULT NULL;
\ No newline at end of file
@@ -0,0 +1,9 @@
+ALTER TABLE users
+    MODIFY COLUMN public_id CHAR(36) NOT NULL;
+
+CREATE UNIQUE INDEX idx_users_public_id ON users(public_id);
+
+ALTER TABLE workspaces
+    MODIFY COLUMN public_id CHAR(36) NOT NULL;
+
+CREATE UNIQUE INDEX idx_workspaces_public_id ON workspaces(public_id);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE outbox_messages DROP COLUMN request_id;
\ No newline at end of file
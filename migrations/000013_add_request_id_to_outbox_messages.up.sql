@@ -0,0 +1,2 @@
+ALTER TABLE outbox_messages
+    ADD COLUMN request_id VARCHAR(128) NOT NULL DEFAULT '' AFTER event_type;
\ No newline at end of file
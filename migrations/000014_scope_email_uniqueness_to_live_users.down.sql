@@ -0,0 +1,8 @@
+-- Fails while a deleted user and a live one share an email
+DROP INDEX idx_users_email_index ON users;
+
+CREATE UNIQUE INDEX idx_users_email_index ON users(email_index);
+
+DROP INDEX idx_users_live_email_index ON users;
+
+ALTER TABLE users DROP COLUMN live_email_index;
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE users
+    ADD COLUMN live_email_index CHAR(64) AS (CASE WHEN deleted_at IS NULL THEN email_index END) VIRTUAL AFTER email_index;
+
+CREATE UNIQUE INDEX idx_users_live_email_index ON users(live_email_index);
+
+DROP INDEX idx_users_email_index ON users;
+
+CREATE INDEX idx_users_email_index ON users(email_index);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE outbox_messages
+    DROP COLUMN claimed_until,
+    DROP COLUMN claim_token;
\ No newline at end of file
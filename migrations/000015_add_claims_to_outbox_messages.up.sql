@@ -0,0 +1,3 @@
+ALTER TABLE outbox_messages
+    ADD COLUMN claim_token VARCHAR(64) NOT NULL DEFAULT '' AFTER dead_at,
+    ADD COLUMN claimed_until DATETIME(3) NULL DEFAULT NULL AFTER claim_token;
\ No newline at end of file
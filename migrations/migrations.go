@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations so the binary can run them from anywhere.
+// Data migrations that need Go live here too and register themselves with migrate.Register.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
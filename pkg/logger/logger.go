@@ -1,53 +1,93 @@
-package logger
-
-import (
-	"os"
-
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
-)
-
-var (
-	TimeFormat = "2006-01-02 15:04:05"
-)
-
-func Init() {
-	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: TimeFormat}
-	logger := zerolog.New(output).With().Timestamp().Logger()
-	log.Logger = logger
-}
-
-func Info(message string, fields map[string]interface{}) {
-	evt := log.Info()
-	for k,v := range fields {
-		evt = evt.Interface(k, v)
-	}
-	evt.Msg(message)
-}
-
-// Error logs an error message with optional fields
-func Error(message string, fields map[string]interface{}) {
-    evt := log.Error()
-    for k, v := range fields {
-        evt = evt.Interface(k, v)
-    }
-    evt.Msg(message)
-}
-
-// Fatal logs a fatal message and exits
-func Fatal(message string, fields map[string]interface{}) {
-    evt := log.Fatal()
-    for k, v := range fields {
-        evt = evt.Interface(k, v)
-    }
-    evt.Msg(message)
-}
-
-// Debug logs a debug message (enable with LOG_LEVEL=debug)
-func Debug(message string, fields map[string]interface{}) {
-    evt := log.Debug()
-    for k, v := range fields {
-        evt = evt.Interface(k, v)
-    }
-    evt.Msg(message)
+package logger
+
+import (
+	"context"
+	"go-clean-v3/pkg/requestid"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	TimeFormat = "2006-01-02 15:04:05"
+)
+
+func Init() {
+	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: TimeFormat}
+	logger := zerolog.New(output).With().Timestamp().Logger().Hook(contextHook{})
+	log.Logger = logger
+}
+
+// contextHook adds the request ID and the trace of the context passed to the
+// *Context functions, so callers never have to add them themselves
+type contextHook struct{}
+
+func (contextHook) Run(e *zerolog.Event, level zerolog.Level, message string) {
+	ctx := e.GetCtx()
+	if id := requestid.FromContext(ctx); id != "" {
+		e.Str("request_id", id)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		e.Str("trace_id", sc.TraceID().String())
+		e.Str("span_id", sc.SpanID().String())
+	}
+}
+
+func Info(message string, fields map[string]interface{}) {
+	evt := log.Info()
+	for k,v := range fields {
+		evt = evt.Interface(k, v)
+	}
+	evt.Msg(message)
+}
+
+// Error logs an error message with optional fields
+func Error(message string, fields map[string]interface{}) {
+    evt := log.Error()
+    for k, v := range fields {
+        evt = evt.Interface(k, v)
+    }
+    evt.Msg(message)
+}
+
+// Fatal logs a fatal message and exits
+func Fatal(message string, fields map[string]interface{}) {
+    evt := log.Fatal()
+    for k, v := range fields {
+        evt = evt.Interface(k, v)
+    }
+    evt.Msg(message)
+}
+
+// Debug logs a debug message (enable with LOG_LEVEL=debug)
+func Debug(message string, fields map[string]interface{}) {
+    evt := log.Debug()
+    for k, v := range fields {
+        evt = evt.Interface(k, v)
+    }
+    evt.Msg(message)
+}
+
+// InfoContext is Info with the request ID and trace in ctx added to the fields
+func InfoContext(ctx context.Context, message string, fields map[string]interface{}) {
+	write(log.Info().Ctx(ctx), message, fields)
+}
+
+// ErrorContext is Error with the request ID and trace in ctx added to the fields
+func ErrorContext(ctx context.Context, message string, fields map[string]interface{}) {
+	write(log.Error().Ctx(ctx), message, fields)
+}
+
+// DebugContext is Debug with the request ID and trace in ctx added to the fields
+func DebugContext(ctx context.Context, message string, fields map[string]interface{}) {
+	write(log.Debug().Ctx(ctx), message, fields)
+}
+
+func write(evt *zerolog.Event, message string, fields map[string]interface{}) {
+	for k, v := range fields {
+		evt = evt.Interface(k, v)
+	}
+	evt.Msg(message)
 }
\ No newline at end of file
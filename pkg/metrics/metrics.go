@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "app"
+
+// Registry holds every metric of the app, along with the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	dbQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Failed database queries by operation and table.",
+	}, []string{"operation", "table"})
+
+	loginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_attempts_total",
+		Help:      "Login attempts by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dbQueryDuration,
+		dbQueryErrors,
+		loginAttempts,
+	)
+}
+
+// Handler serves the metrics of Registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTPRequest records a finished request. route must be the route
+// template, never the raw path, to keep the number of series bounded.
+func ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveQuery records a finished database query and whether it failed
+func ObserveQuery(operation string, table string, duration time.Duration, failed bool) {
+	dbQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+	if failed {
+		dbQueryErrors.WithLabelValues(operation, table).Inc()
+	}
+}
+
+// LoginSucceeded counts a successful login
+func LoginSucceeded() {
+	loginAttempts.WithLabelValues("success").Inc()
+}
+
+// LoginFailed counts a rejected login
+func LoginFailed() {
+	loginAttempts.WithLabelValues("failure").Inc()
+}
+
+// RegisterDBStats exports the connection pool statistics returned by stats as
+// gauges, labeled by pool name. They are read on every scrape.
+func RegisterDBStats(stats func() map[string]sql.DBStats) error {
+	return Registry.Register(dbStatsCollector{stats: stats})
+}
+
+var (
+	dbMaxOpenDesc      = dbStatsDesc("max_open_connections", "Maximum number of open connections to the database.")
+	dbOpenDesc         = dbStatsDesc("open_connections", "Number of established connections, in use and idle.")
+	dbInUseDesc        = dbStatsDesc("in_use_connections", "Number of connections currently in use.")
+	dbIdleDesc         = dbStatsDesc("idle_connections", "Number of idle connections.")
+	dbWaitCountDesc    = dbStatsDesc("wait_count_total", "Total number of connections waited for.")
+	dbWaitDurationDesc = dbStatsDesc("wait_duration_seconds_total", "Total time blocked waiting for a new connection.")
+	dbMaxIdleDesc      = dbStatsDesc("max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns.")
+	dbMaxIdleTimeDesc  = dbStatsDesc("max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime.")
+	dbMaxLifetimeDesc  = dbStatsDesc("max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime.")
+)
+
+func dbStatsDesc(name string, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, []string{"pool"}, nil)
+}
+
+// dbStatsCollector is a prometheus.Collector over sql.DBStats
+type dbStatsCollector struct {
+	stats func() map[string]sql.DBStats
+}
+
+// Describe implements prometheus.Collector.
+func (c dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbMaxOpenDesc
+	ch <- dbOpenDesc
+	ch <- dbInUseDesc
+	ch <- dbIdleDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitDurationDesc
+	ch <- dbMaxIdleDesc
+	ch <- dbMaxIdleTimeDesc
+	ch <- dbMaxLifetimeDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for pool, s := range c.stats() {
+		ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(s.MaxOpenConnections), pool)
+		ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.OpenConnections), pool)
+		ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(s.InUse), pool)
+		ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(s.Idle), pool)
+		ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(s.WaitCount), pool)
+		ch <- prometheus.MustNewConstMetric(dbWaitDurationDesc, prometheus.CounterValue, s.WaitDuration.Seconds(), pool)
+		ch <- prometheus.MustNewConstMetric(dbMaxIdleDesc, prometheus.CounterValue, float64(s.MaxIdleClosed), pool)
+		ch <- prometheus.MustNewConstMetric(dbMaxIdleTimeDesc, prometheus.CounterValue, float64(s.MaxIdleTimeClosed), pool)
+		ch <- prometheus.MustNewConstMetric(dbMaxLifetimeDesc, prometheus.CounterValue, float64(s.MaxLifetimeClosed), pool)
+	}
+}
@@ -0,0 +1,32 @@
+// Package publicid generates the identifiers entities are known by outside the
+// app. They are UUIDv7s: random enough not to be guessed or counted, and ordered
+// by creation time so they index well.
+package publicid
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// New returns a new public ID
+func New() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// At returns a public ID carrying t as its timestamp, for rows created before
+// they had public IDs
+func At(t time.Time) string {
+	id := uuid.Must(uuid.NewV7())
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixMilli()))
+	copy(id[:6], ms[2:])
+	return id.String()
+}
+
+// Valid reports whether s is in the format of a public ID
+func Valid(s string) bool {
+	id, err := uuid.Parse(s)
+	return err == nil && len(s) == 36 && id.Version() == 7
+}
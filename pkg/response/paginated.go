@@ -0,0 +1,65 @@
+package response
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Meta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type PaginatedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Data       interface{} `json:"data"`
+	Meta       Meta        `json:"meta"`
+}
+
+// Paginated writes a page of data and advertises the neighbouring pages in a Link header
+func Paginated(c echo.Context, status int, data interface{}, meta Meta) error {
+	if link := linkHeader(c.Request().URL, meta); link != "" {
+		c.Response().Header().Set("Link", link)
+	}
+
+	return c.JSON(status, PaginatedResponse{
+		StatusCode: status,
+		Data:       data,
+		Meta:       meta,
+	})
+}
+
+func linkHeader(u *url.URL, meta Meta) string {
+	var links []string
+
+	if meta.NextCursor != "" {
+		q := u.Query()
+		q.Del("offset")
+		q.Set("cursor", meta.NextCursor)
+		links = append(links, pageLink(u, q, "next"))
+	}
+
+	// Offset pages can also link backwards; cursors only move forward
+	if u.Query().Get("cursor") == "" && meta.Offset > 0 {
+		q := u.Query()
+		prev := meta.Offset - meta.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		q.Set("offset", strconv.Itoa(prev))
+		links = append(links, pageLink(u, q, "prev"))
+	}
+
+	return strings.Join(links, ", ")
+}
+
+func pageLink(u *url.URL, q url.Values, rel string) string {
+	target := url.URL{Path: u.Path, RawQuery: q.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, target.String(), rel)
+}
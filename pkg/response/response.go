@@ -1,27 +1,29 @@
-package response
-
-import "github.com/labstack/echo/v4"
-
-type Response struct {
-	StatusCode int		`json:"status_code"`
-	Data interface{} `json:"data,omitempty"`
-	Error string `json:"error,omitempty"`
-
-}
-
-func JSON(c echo.Context, status int, data interface{}) error {
-response := Response{
-		StatusCode: status,
-		Data: data,
-	}
-	return c.JSON(status, response)
-}
-
-func Error(c echo.Context, status int, message string, err error) error {
-	response := Response{
-		StatusCode: status,
-		Error: message,
-	}
-
-	return c.JSON(status, response)
+package response
+
+import "github.com/labstack/echo/v4"
+
+type Response struct {
+	StatusCode int		`json:"status_code"`
+	Data interface{} `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+
+}
+
+func JSON(c echo.Context, status int, data interface{}) error {
+response := Response{
+		StatusCode: status,
+		Data: data,
+	}
+	return c.JSON(status, response)
+}
+
+// Error reports err to the client with status and message. The response is
+// written as problem details by the HTTP error handler, which logs err only when
+// status is 500 or above; client errors are left to the access log.
+func Error(c echo.Context, status int, message string, err error) error {
+	return &HTTPError{
+		Status:  status,
+		Message: message,
+		Err:     err,
+	}
 }
\ No newline at end of file
@@ -0,0 +1,117 @@
+// Package integration provides the setup shared by integration tests. They run
+// against the MySQL server in TEST_DB_URL and are skipped when it is unset.
+package integration
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"go-clean-v3/internal/config"
+	"go-clean-v3/internal/domain/audit"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/internal/domain/transaction"
+	userDomain "go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/infrastructure/external/jwt"
+	"go-clean-v3/internal/infrastructure/persistence/gorm"
+	"go-clean-v3/internal/infrastructure/persistence/migrate"
+	"go-clean-v3/internal/seed"
+	"go-clean-v3/internal/usecase/auth"
+	"go-clean-v3/internal/usecase/eventbus"
+	"go-clean-v3/internal/usecase/user"
+	"go-clean-v3/internal/usecase/workspace"
+	"go-clean-v3/migrations"
+
+	gormlib "gorm.io/gorm"
+)
+
+// testKey is the master and blind index key of test databases
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+// Env is a freshly migrated database with the repositories and usecases wired
+// up like in cmd/server
+type Env struct {
+	DB         *gormlib.DB
+	UserRepo   userDomain.UserRepositoryInterface
+	AuditRepo  audit.AuditRepositoryInterface
+	OutboxRepo event.OutboxRepositoryInterface
+	// Events lets tests subscribe to the events raised by the usecases
+	Events     *eventbus.Bus
+	TxManager  transaction.Manager
+	Users      *user.UserUsecase
+	Auth       *auth.AuthUsecase
+	Workspaces *workspace.WorkspaceUsecase
+}
+
+// Setup creates a scratch database next to TEST_DB_URL, migrates it and loads
+// the given seed sets. The database is dropped when the test finishes.
+func Setup(t testing.TB, sets ...string) *Env {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_URL")
+	if dsn == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	scratch, drop, err := migrate.Scratch(dsn, "it")
+	if err != nil {
+		t.Fatalf("could not create test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := drop(); err != nil {
+			t.Errorf("could not drop test database: %v", err)
+		}
+	})
+
+	if err := migrate.Run(scratch, migrations.FS); err != nil {
+		t.Fatalf("could not migrate test database: %v", err)
+	}
+
+	db, err := gorm.NewDB(&config.Config{
+		DatabaseURL:             scratch,
+		DatabaseMaxOpenConns:    10,
+		DatabaseMaxIdleConns:    10,
+		DatabaseConnMaxLifetime: time.Minute,
+		DatabaseConnMaxIdleTime: time.Minute,
+		DatabaseConnectTimeout:  10 * time.Second,
+		EncryptionKeys:          "test:" + testKey,
+		EncryptionActiveKey:     "test",
+		EncryptionIndexKey:      testKey,
+	})
+	if err != nil {
+		t.Fatalf("could not connect to test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("could not connect to test database: %v", err)
+	}
+	// Registered after drop, so it runs first
+	t.Cleanup(func() { sqlDB.Close() })
+
+	env := &Env{
+		DB:         db,
+		UserRepo:   gorm.NewUserRepository(db),
+		AuditRepo:  gorm.NewAuditRepository(db),
+		OutboxRepo: gorm.NewOutboxRepository(db),
+		TxManager:  gorm.NewTransactionManager(db),
+	}
+	if env.Events, err = eventbus.NewBus(env.OutboxRepo, 2, 100, 3); err != nil {
+		t.Fatalf("could not create event bus: %v", err)
+	}
+	t.Cleanup(func() { env.Events.Shutdown(context.Background()) })
+
+	jwtService := jwt.NewJWTService("integration-test-secret")
+	env.Users = user.NewUserUsecase(env.UserRepo, jwtService, env.TxManager, env.AuditRepo, env.Events)
+	env.Auth = auth.NewAuthUsecase(env.UserRepo, jwtService, env.AuditRepo)
+	env.Workspaces = workspace.NewWorkspaceUsecase(gorm.NewWorkspaceRepository(db), env.UserRepo, jwtService, env.TxManager, env.AuditRepo)
+
+	if len(sets) > 0 {
+		seeder := seed.NewSeeder(env.Users, env.UserRepo, seed.Options{LoadTestUsers: 100})
+		if err := seeder.Run(context.Background(), sets...); err != nil {
+			t.Fatalf("could not seed test database: %v", err)
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,113 @@
+package integration_test
+
+import (
+	"context"
+	"errors"
+	"go-clean-v3/internal/domain/event"
+	"go-clean-v3/test/integration"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	ID string `json:"id"`
+	N  int    `json:"n"`
+}
+
+func (testEvent) EventName() string     { return "test.happened" }
+func (testEvent) AggregateType() string { return "test" }
+func (e testEvent) AggregateID() string { return e.ID }
+
+func aggregateCounts(messages []*event.Message) map[string]int {
+	ids := map[string]int{}
+	for _, msg := range messages {
+		ids[msg.AggregateID]++
+	}
+	return ids
+}
+
+func TestOutboxClaimsOldestMessagePerAggregate(t *testing.T) {
+	env := integration.Setup(t)
+	ctx := context.Background()
+
+	if err := env.OutboxRepo.Record(ctx, testEvent{ID: "a", N: 1}, testEvent{ID: "a", N: 2}, testEvent{ID: "b", N: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := env.OutboxRepo.ClaimPending(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := aggregateCounts(claimed); len(claimed) != 2 || ids["a"] != 1 || ids["b"] != 1 {
+		t.Fatalf("claimed %v, want the first message of a and b", ids)
+	}
+
+	// Claimed messages are not handed out again, and hold back later ones
+	again, err := env.OutboxRepo.ClaimPending(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Fatalf("claimed %d messages twice", len(again))
+	}
+
+	for _, msg := range claimed {
+		if err := env.OutboxRepo.MarkPublished(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next, err := env.OutboxRepo.ClaimPending(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next) != 1 || next[0].AggregateID != "a" || next[0].Attempts != 0 {
+		t.Fatalf("claimed %v after publishing, want the second message of a", aggregateCounts(next))
+	}
+}
+
+func TestOutboxMarkFailedSchedulesRetry(t *testing.T) {
+	env := integration.Setup(t)
+	ctx := context.Background()
+
+	if err := env.OutboxRepo.Record(ctx, testEvent{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := env.OutboxRepo.ClaimPending(ctx, 10, time.Minute)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("claimed %d messages, err %v", len(claimed), err)
+	}
+
+	if err := env.OutboxRepo.MarkFailed(ctx, claimed[0], errors.New("receiver is down"), time.Now().Add(time.Hour), false); err != nil {
+		t.Fatal(err)
+	}
+	// Not due before the next attempt, even though the claim is settled
+	if again, _ := env.OutboxRepo.ClaimPending(ctx, 10, time.Minute); len(again) != 0 {
+		t.Fatal("a message was claimed before its next attempt")
+	}
+}
+
+func TestOutboxExpiredClaimIsTakenOver(t *testing.T) {
+	env := integration.Setup(t)
+	ctx := context.Background()
+
+	if err := env.OutboxRepo.Record(ctx, testEvent{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := env.OutboxRepo.ClaimPending(ctx, 10, 10*time.Millisecond)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("claimed %d messages, err %v", len(first), err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	second, err := env.OutboxRepo.ClaimPending(ctx, 10, time.Minute)
+	if err != nil || len(second) != 1 {
+		t.Fatalf("claimed %d messages after the lease ran out, err %v", len(second), err)
+	}
+
+	if err := env.OutboxRepo.MarkPublished(ctx, first[0]); !errors.Is(err, event.ErrClaimLost) {
+		t.Errorf("marking with the expired claim: err = %v, want ErrClaimLost", err)
+	}
+	if err := env.OutboxRepo.MarkPublished(ctx, second[0]); err != nil {
+		t.Errorf("marking with the current claim: %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+package integration_test
+
+import (
+	"go-clean-v3/internal/infrastructure/persistence/drift/drifttest"
+	"testing"
+)
+
+func TestMigrationsMatchModels(t *testing.T) {
+	drifttest.RequireNoDrift(t, "")
+}
@@ -0,0 +1,106 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"go-clean-v3/internal/domain/query"
+	"go-clean-v3/internal/domain/user"
+	"go-clean-v3/internal/seed"
+	userUsecase "go-clean-v3/internal/usecase/user"
+	"go-clean-v3/test/integration"
+	"strings"
+	"testing"
+)
+
+func TestSeededUsersCanLogIn(t *testing.T) {
+	env := integration.Setup(t, "minimal")
+	ctx := context.Background()
+
+	for _, email := range []string{seed.AdminEmail, seed.UserEmail} {
+		token, err := env.Auth.Login(ctx, userUsecase.LoginUserRequest{Email: email, Password: seed.DemoPassword})
+		if err != nil {
+			t.Fatalf("%s: Login: %v", email, err)
+		}
+		if token == "" {
+			t.Errorf("%s: Login returned an empty token", email)
+		}
+	}
+
+	_, err := env.Auth.Login(ctx, userUsecase.LoginUserRequest{Email: seed.UserEmail, Password: "wrong-password"})
+	if !errors.Is(err, user.ErrInvalidCredentials) {
+		t.Errorf("Login with a wrong password: err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestRegisterRejectsTakenEmail(t *testing.T) {
+	env := integration.Setup(t, "minimal")
+
+	_, err := env.Users.Register(context.Background(), userUsecase.RegisterUserRequest{
+		Name:     "Another User",
+		Email:    "  USER@example.com ",
+		Password: "Password123",
+	})
+	if !errors.Is(err, user.ErrEmailExists) {
+		t.Errorf("err = %v, want ErrEmailExists", err)
+	}
+}
+
+func TestDeletedUserReleasesEmail(t *testing.T) {
+	env := integration.Setup(t, "minimal")
+	ctx := context.Background()
+
+	existing, err := env.UserRepo.GetByEmail(ctx, seed.UserEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Users.DeleteAccount(ctx, existing.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.Users.Register(ctx, userUsecase.RegisterUserRequest{
+		Name:     "New User",
+		Email:    seed.UserEmail,
+		Password: "Password123",
+	}); err != nil {
+		t.Fatalf("Register after the old account was deleted: %v", err)
+	}
+
+	if err := env.UserRepo.Restore(ctx, existing.ID); !errors.Is(err, user.ErrEmailExists) {
+		t.Errorf("Restore: err = %v, want ErrEmailExists", err)
+	}
+}
+
+func TestUserListCursorIsSealed(t *testing.T) {
+	env := integration.Setup(t, "minimal")
+	ctx := context.Background()
+	spec := query.Spec{Limit: 1, Sort: []query.Sort{{Field: "name", Direction: query.Asc}}}
+
+	var names []string
+	for {
+		page, err := env.UserRepo.List(ctx, spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range page.Items {
+			names = append(names, u.Name)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		// The cursor holds the internal ID of the last user, which must not show
+		if raw, err := base64.RawURLEncoding.DecodeString(page.NextCursor); err == nil && strings.Contains(string(raw), `"v"`) {
+			t.Fatalf("cursor %q is readable", page.NextCursor)
+		}
+		spec.Cursor = page.NextCursor
+	}
+	if len(names) != 2 || names[0] >= names[1] {
+		t.Errorf("paged through %v, want both seeded users by name", names)
+	}
+
+	// A cursor built by the client is refused
+	spec.Cursor = query.EncodeCursor(spec.Sort, []interface{}{"A"}, 1)
+	if _, err := env.UserRepo.List(ctx, spec); !errors.Is(err, query.ErrInvalidQuery) {
+		t.Errorf("forged cursor: err = %v, want ErrInvalidQuery", err)
+	}
+}